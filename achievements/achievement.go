@@ -1 +1,224 @@
 package achievements
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"something/events"
+)
+
+// Definition describes an achievement and what unlocks it.
+type Definition struct {
+	ID          string      `json:"id"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	Event       events.Type `json:"event"`    // Event type that advances progress
+	Subject     string      `json:"subject"`  // Optional filter on Event.Subject
+	Target      float64     `json:"target"`   // Progress needed to unlock (defaults to 1)
+	Requires    []string    `json:"requires"` // Achievement IDs that must be unlocked first
+}
+
+// LoadDefinitions reads achievement definitions from a JSON file and checks
+// for duplicate IDs, unknown prerequisites and prerequisite cycles.
+func LoadDefinitions(path string) ([]Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read achievements: %w", err)
+	}
+	var defs []Definition
+	if err := json.Unmarshal(data, &defs); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	byID := make(map[string]*Definition)
+	var errs []error
+	for i := range defs {
+		d := &defs[i]
+		if d.Target <= 0 {
+			d.Target = 1
+		}
+		if _, dup := byID[d.ID]; dup {
+			errs = append(errs, fmt.Errorf("duplicate achievement %q", d.ID))
+		}
+		byID[d.ID] = d
+	}
+	for _, d := range defs {
+		for _, req := range d.Requires {
+			if _, ok := byID[req]; !ok {
+				errs = append(errs, fmt.Errorf("achievement %q requires unknown %q", d.ID, req))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	state := make(map[string]int) // 1 = visiting, 2 = done
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case 1:
+			return fmt.Errorf("achievement prerequisite cycle at %q", id)
+		case 2:
+			return nil
+		}
+		state[id] = 1
+		for _, req := range byID[id].Requires {
+			if err := visit(req); err != nil {
+				return err
+			}
+		}
+		state[id] = 2
+		return nil
+	}
+	for _, d := range defs {
+		if err := visit(d.ID); err != nil {
+			return nil, err
+		}
+	}
+	return defs, nil
+}
+
+// Tracker counts event progress for one player profile and publishes
+// AchievementUnlocked events on the bus.
+type Tracker struct {
+	mu       sync.Mutex
+	defs     []Definition
+	bus      *events.Bus
+	Progress map[string]float64   `json:"progress"`
+	Unlocked map[string]time.Time `json:"unlocked"`
+}
+
+// NewTracker subscribes a tracker to every event type used by defs.
+func NewTracker(defs []Definition, bus *events.Bus) *Tracker {
+	t := &Tracker{
+		defs:     defs,
+		bus:      bus,
+		Progress: make(map[string]float64),
+		Unlocked: make(map[string]time.Time),
+	}
+	subscribed := make(map[events.Type]bool)
+	for _, d := range defs {
+		if subscribed[d.Event] {
+			continue
+		}
+		subscribed[d.Event] = true
+		bus.Subscribe(d.Event, t.handle)
+	}
+	return t
+}
+
+// Definitions returns the achievements known to the tracker.
+func (t *Tracker) Definitions() []Definition {
+	return t.defs
+}
+
+// IsUnlocked reports whether the achievement has been earned.
+func (t *Tracker) IsUnlocked(id string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.Unlocked[id]
+	return ok
+}
+
+func (t *Tracker) handle(e events.Event) {
+	t.mu.Lock()
+	for _, d := range t.defs {
+		if d.Event != e.Type || (d.Subject != "" && d.Subject != e.Subject) {
+			continue
+		}
+		if _, done := t.Unlocked[d.ID]; done {
+			continue
+		}
+		amount := e.Amount
+		if amount == 0 {
+			amount = 1
+		}
+		t.Progress[d.ID] += amount
+	}
+	unlocked := t.unlockReady()
+	t.mu.Unlock()
+
+	// Publish outside the lock so subscribers may query the tracker
+	for _, d := range unlocked {
+		t.bus.Publish(events.Event{Type: events.AchievementUnlocked, Subject: d.ID})
+	}
+}
+
+// unlockReady unlocks every achievement whose target and prerequisites are
+// met, repeating until no more unlock. Callers must hold t.mu.
+func (t *Tracker) unlockReady() []Definition {
+	var unlocked []Definition
+	for changed := true; changed; {
+		changed = false
+		for _, d := range t.defs {
+			if _, done := t.Unlocked[d.ID]; done || t.Progress[d.ID] < d.Target {
+				continue
+			}
+			ready := true
+			for _, req := range d.Requires {
+				if _, ok := t.Unlocked[req]; !ok {
+					ready = false
+					break
+				}
+			}
+			if !ready {
+				continue
+			}
+			t.Unlocked[d.ID] = time.Now()
+			unlocked = append(unlocked, d)
+			changed = true
+		}
+	}
+	return unlocked
+}
+
+// Load restores progress from a profile file. A missing file is not an error.
+func (t *Tracker) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read profile achievements: %w", err)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := json.Unmarshal(data, t); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if t.Progress == nil {
+		t.Progress = make(map[string]float64)
+	}
+	if t.Unlocked == nil {
+		t.Unlocked = make(map[string]time.Time)
+	}
+	return nil
+}
+
+// Save writes progress and unlocks to a profile file.
+func (t *Tracker) Save(path string) error {
+	t.mu.Lock()
+	data, err := json.MarshalIndent(t, "", "  ")
+	t.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Title returns the display title for an achievement ID.
+func (t *Tracker) Title(id string) string {
+	for _, d := range t.defs {
+		if d.ID == id {
+			return d.Title
+		}
+	}
+	return id
+}
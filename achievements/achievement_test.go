@@ -0,0 +1,148 @@
+package achievements
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"something/events"
+)
+
+// writeDefs writes a definition file into a temporary directory.
+func writeDefs(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "achievements.json")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDefinitions(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"valid", `[{"id": "a", "event": "block_broken"}, {"id": "b", "event": "block_placed", "target": 5, "requires": ["a"]}]`, ""},
+		{"duplicate", `[{"id": "a"}, {"id": "a"}]`, `duplicate achievement "a"`},
+		{"unknown prerequisite", `[{"id": "a", "requires": ["ghost"]}]`, `achievement "a" requires unknown "ghost"`},
+		{"cycle", `[{"id": "a", "requires": ["c"]}, {"id": "b", "requires": ["a"]}, {"id": "c", "requires": ["b"]}]`, "prerequisite cycle"},
+		{"requires itself", `[{"id": "a", "requires": ["a"]}]`, `prerequisite cycle at "a"`},
+		{"bad JSON", `[{"id": }]`, "failed to parse"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defs, err := LoadDefinitions(writeDefs(t, tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if defs[0].Target != 1 || defs[1].Target != 5 {
+				t.Errorf("targets %v and %v, want the default 1 and 5", defs[0].Target, defs[1].Target)
+			}
+		})
+	}
+	if _, err := LoadDefinitions(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("loaded a missing file")
+	}
+	if _, err := LoadDefinitions("../assets/achievements.json"); err != nil {
+		t.Errorf("bundled definitions: %v", err)
+	}
+}
+
+const testDefs = `[
+	{"id": "first_block", "event": "block_broken"},
+	{"id": "stone_age", "event": "block_broken", "subject": "stone", "target": 3, "requires": ["first_block"]},
+	{"id": "walker", "event": "distance_travelled", "target": 100},
+	{"id": "explorer", "event": "chunk_generated", "target": 2, "requires": ["walker"]},
+	{"id": "veteran", "event": "player_died", "target": 99, "requires": ["explorer"]}
+]`
+
+// newTestTracker returns a tracker for testDefs and the IDs it announces.
+func newTestTracker(t *testing.T) (*Tracker, *events.Bus, *[]string) {
+	t.Helper()
+	defs, err := LoadDefinitions(writeDefs(t, testDefs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus := events.NewBus()
+	tracker := NewTracker(defs, bus)
+	var announced []string
+	bus.Subscribe(events.AchievementUnlocked, func(e events.Event) {
+		// Subscribers may query the tracker while it announces
+		if !tracker.IsUnlocked(e.Subject) {
+			t.Errorf("announced %q before unlocking it", e.Subject)
+		}
+		announced = append(announced, e.Subject)
+	})
+	return tracker, bus, &announced
+}
+
+func TestTrackerProgress(t *testing.T) {
+	tracker, bus, announced := newTestTracker(t)
+	steps := []struct {
+		event events.Event
+		want  []string // Announced so far
+	}{
+		{events.Event{Type: events.BlockBroken, Subject: "stone", Amount: 1}, []string{"first_block"}},
+		{events.Event{Type: events.BlockBroken, Subject: "dirt", Amount: 1}, []string{"first_block"}},
+		{events.Event{Type: events.BlockBroken, Subject: "stone"}, []string{"first_block"}}, // No amount counts as 1
+		{events.Event{Type: events.BlockBroken, Subject: "stone", Amount: 1}, []string{"first_block", "stone_age"}},
+		// Explorer reaches its target before its prerequisite
+		{events.Event{Type: events.ChunkGenerated, Amount: 1}, []string{"first_block", "stone_age"}},
+		{events.Event{Type: events.ChunkGenerated, Amount: 1}, []string{"first_block", "stone_age"}},
+		{events.Event{Type: events.DistanceTravelled, Amount: 60}, []string{"first_block", "stone_age"}},
+		// Unlocking the prerequisite unlocks what waited on it
+		{events.Event{Type: events.DistanceTravelled, Amount: 60}, []string{"first_block", "stone_age", "walker", "explorer"}},
+		{events.Event{Type: events.PlayerDied, Amount: 1}, []string{"first_block", "stone_age", "walker", "explorer"}},
+	}
+	for i, s := range steps {
+		bus.Publish(s.event)
+		if !slices.Equal(*announced, s.want) {
+			t.Fatalf("step %d: announced %v, want %v", i, *announced, s.want)
+		}
+	}
+	if tracker.Progress["first_block"] != 1 {
+		t.Errorf("first_block progress %v after unlocking, want it frozen at 1", tracker.Progress["first_block"])
+	}
+	if tracker.Progress["walker"] != 120 || tracker.Progress["veteran"] != 1 {
+		t.Errorf("progress %v", tracker.Progress)
+	}
+	if tracker.IsUnlocked("veteran") {
+		t.Error("veteran unlocked below its target")
+	}
+}
+
+func TestTrackerSaveLoad(t *testing.T) {
+	tracker, bus, _ := newTestTracker(t)
+	bus.Publish(events.Event{Type: events.BlockBroken, Subject: "stone", Amount: 2})
+	path := filepath.Join(t.TempDir(), "profiles", "player.json")
+	if err := tracker.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, bus, announced := newTestTracker(t)
+	if err := restored.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if !restored.IsUnlocked("first_block") || restored.Progress["stone_age"] != 2 {
+		t.Fatalf("restored unlocked %v progress %v", restored.Unlocked, restored.Progress)
+	}
+	// Progress continues from the saved state and is not announced twice
+	bus.Publish(events.Event{Type: events.BlockBroken, Subject: "stone", Amount: 1})
+	if !slices.Equal(*announced, []string{"stone_age"}) {
+		t.Errorf("announced %v after loading, want only stone_age", *announced)
+	}
+
+	if err := restored.Load(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Errorf("Load of a missing file: %v", err)
+	}
+}
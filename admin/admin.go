@@ -0,0 +1,210 @@
+// Package admin serves a token-protected JSON API for inspecting and
+// scripting a running world over HTTP. Handlers never touch the game
+// directly: each request queues a job that the game loop runs between
+// frames, so requests see a consistent world and GL work stays on the main
+// thread.
+package admin
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"something/commands"
+	"something/stats"
+)
+
+// DefaultAddr is where the API listens unless told otherwise. It is bound
+// to loopback so only local tools can reach it.
+const DefaultAddr = "127.0.0.1:8081"
+
+// jobTimeout bounds how long a request waits for the game loop. It is a
+// variable so tests can shorten it.
+var jobTimeout = 5 * time.Second
+
+//go:embed openapi.json
+var openAPI []byte
+
+// Server is the admin API for one game.
+type Server struct {
+	Game  *commands.Game // Player and Entities may be nil
+	Stats *stats.Stats   // nil without a player
+	Token string         // Required as "Authorization: Bearer <token>"
+
+	jobs chan func()
+	http *http.Server
+}
+
+func New(g *commands.Game, st *stats.Stats, token string) *Server {
+	return &Server{Game: g, Stats: st, Token: token, jobs: make(chan func(), 64)}
+}
+
+// NewToken returns a random token for when none is configured.
+func NewToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate admin token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Start creates a server and listens on addr. An empty token is replaced
+// with a random one, which callers should show to the user.
+func Start(addr, token string, g *commands.Game, st *stats.Stats) (*Server, net.Addr, error) {
+	if token == "" {
+		var err error
+		if token, err = NewToken(); err != nil {
+			return nil, nil, err
+		}
+	}
+	s := New(g, st, token)
+	bound, err := s.Listen(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, bound, nil
+}
+
+// Jobs is the queue of requests waiting to run on the game loop, for loops
+// that select on several sources. Each job must be called once.
+func (s *Server) Jobs() <-chan func() {
+	return s.jobs
+}
+
+// Process runs every queued job without blocking. Call it once per frame.
+func (s *Server) Process() {
+	for {
+		select {
+		case job := <-s.jobs:
+			job()
+		default:
+			return
+		}
+	}
+}
+
+var errBusy = errors.New("game loop did not respond")
+
+// Job states, so that a job and a request that gave up on it agree on
+// whether it ran.
+const (
+	jobQueued int32 = iota
+	jobRunning
+	jobAbandoned
+)
+
+// do runs fn on the game loop and waits for it to finish. If the wait ends
+// before the loop picks the job up, fn is skipped, so a request that
+// reports failure never takes effect later.
+func (s *Server) do(ctx context.Context, fn func()) error {
+	ctx, cancel := context.WithTimeout(ctx, jobTimeout)
+	defer cancel()
+	var state atomic.Int32
+	done := make(chan struct{})
+	job := func() {
+		if state.CompareAndSwap(jobQueued, jobRunning) {
+			fn()
+		}
+		close(done)
+	}
+	select {
+	case s.jobs <- job:
+	case <-ctx.Done():
+		return errBusy
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		if state.CompareAndSwap(jobQueued, jobAbandoned) {
+			return errBusy
+		}
+		// Already running, so it is about to finish
+		<-done
+		return nil
+	}
+}
+
+// Handler returns the API's routes. Everything but the OpenAPI description
+// needs the token.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPI)
+	})
+	routes := map[string]http.HandlerFunc{
+		"GET /api/player":             s.getPlayer,
+		"POST /api/player/teleport":   s.teleport,
+		"GET /api/chunks":             s.listChunks,
+		"GET /api/blocks/{x}/{y}/{z}": s.getBlock,
+		"PUT /api/blocks/{x}/{y}/{z}": s.setBlock,
+		"GET /api/entities":           s.listEntities,
+		"GET /api/stats":              s.getStats,
+	}
+	for pattern, h := range routes {
+		mux.Handle(pattern, s.authorize(h))
+	}
+	return mux
+}
+
+// authorize rejects requests without the bearer token.
+func (s *Server) authorize(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || s.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			writeError(w, http.StatusUnauthorized, "missing or invalid token")
+			return
+		}
+		next(w, r)
+	})
+}
+
+// Listen starts serving the API on addr in the background.
+func (s *Server) Listen(addr string) (net.Addr, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for admin API: %w", err)
+	}
+	s.http = &http.Server{
+		Handler:      s.Handler(),
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := s.http.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("admin API stopped: %v", err)
+		}
+	}()
+	return ln.Addr(), nil
+}
+
+// Close stops the listener started by Listen.
+func (s *Server) Close() error {
+	if s.http == nil {
+		return nil
+	}
+	return s.http.Close()
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,266 @@
+package admin
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"something/block"
+	"something/commands"
+	"something/entities"
+	"something/physics"
+	"something/world"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const testToken = "secret"
+
+// newTestServer serves the API for a headless world with chunks -1..1
+// loaded, a player and two entities. With loop set, a goroutine drains
+// jobs the way the game loop does.
+func newTestServer(t *testing.T, loop bool) (*httptest.Server, *Server) {
+	t.Helper()
+	w := &world.World{Chunks: make(map[[2]int]*world.Chunk), ChunkRadius: 1, Headless: true}
+	w.UpdateChunks(mgl32.Vec3{})
+	m := entities.NewManager(w)
+	m.RegisterType("pig", func(m *entities.Manager, id entities.EntityID, t entities.Transform) error { return nil })
+	for _, pos := range []mgl32.Vec3{{1, 8, 2}, {-3, 9, 4}} {
+		if _, err := m.Spawn("pig", entities.Transform{Position: pos, Yaw: 90}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g := &commands.Game{World: w, Entities: m, Player: &physics.Body{Position: mgl32.Vec3{0, 10, 0}, Velocity: mgl32.Vec3{1, 0, 0}}}
+	s := New(g, nil, testToken)
+	srv := httptest.NewServer(s.Handler())
+	t.Cleanup(srv.Close)
+	if loop {
+		stop := make(chan struct{})
+		t.Cleanup(func() { close(stop) })
+		go func() {
+			tick := time.NewTicker(time.Millisecond)
+			defer tick.Stop()
+			for {
+				select {
+				case <-stop:
+					return
+				case <-tick.C:
+					s.Process()
+				}
+			}
+		}()
+	}
+	return srv, s
+}
+
+// call sends a request with the test token and decodes a JSON response
+// into out, if given. It returns the status code.
+func call(t *testing.T, srv *httptest.Server, method, path, body string, out any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != nil && resp.StatusCode < 300 {
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatalf("%s %s: %v in %s", method, path, err, data)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestAuthorization(t *testing.T) {
+	srv, _ := newTestServer(t, true)
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"missing", "", http.StatusUnauthorized},
+		{"wrong token", "Bearer nope", http.StatusUnauthorized},
+		{"wrong scheme", "Basic " + testToken, http.StatusUnauthorized},
+		{"valid", "Bearer " + testToken, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, srv.URL+"/api/player", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status %d, want %d", resp.StatusCode, tt.want)
+			}
+			if tt.want == http.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") == "" {
+				t.Error("401 without a WWW-Authenticate header")
+			}
+		})
+	}
+
+	resp, err := srv.Client().Get(srv.URL + "/api/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var doc map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("openapi.json without a token: status %d, %v", resp.StatusCode, err)
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	srv, s := newTestServer(t, true)
+	g := s.Game
+	before := g.World.GetBlock(0, 5, 0)
+	tests := []struct {
+		name, method, path, body string
+		want                     int
+		block                    string
+	}{
+		{"set", http.MethodPut, "/api/blocks/3/5/-7", `{"block": "stone"}`, http.StatusOK, "stone"},
+		{"get", http.MethodGet, "/api/blocks/3/5/-7", "", http.StatusOK, "stone"},
+		{"set air", http.MethodPut, "/api/blocks/-16/15/15", `{"block": "air"}`, http.StatusOK, "air"},
+		{"get unloaded", http.MethodGet, "/api/blocks/100/5/0", "", http.StatusNotFound, ""},
+		{"set unloaded", http.MethodPut, "/api/blocks/0/5/-17", `{"block": "dirt"}`, http.StatusNotFound, ""},
+		{"y below the world", http.MethodGet, "/api/blocks/0/-1/0", "", http.StatusBadRequest, ""},
+		{"y above the world", http.MethodPut, "/api/blocks/0/16/0", `{"block": "dirt"}`, http.StatusBadRequest, ""},
+		{"bad coordinate", http.MethodGet, "/api/blocks/a/1/0", "", http.StatusBadRequest, ""},
+		{"unknown block", http.MethodPut, "/api/blocks/0/5/0", `{"block": "lava"}`, http.StatusBadRequest, ""},
+		{"bad body", http.MethodPut, "/api/blocks/0/5/0", `stone`, http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b Block
+			if got := call(t, srv, tt.method, tt.path, tt.body, &b); got != tt.want {
+				t.Fatalf("status %d, want %d", got, tt.want)
+			}
+			if tt.want == http.StatusOK && b.Block != tt.block {
+				t.Errorf("block %q, want %q", b.Block, tt.block)
+			}
+		})
+	}
+	if id := g.World.GetBlock(3, 5, -7); id != block.BlockStone {
+		t.Errorf("world holds block %d at 3 5 -7, want stone", id)
+	}
+	if id := g.World.GetBlock(0, 5, 0); id != before {
+		t.Error("a rejected request changed the world")
+	}
+}
+
+func TestTeleport(t *testing.T) {
+	srv, s := newTestServer(t, true)
+	g := s.Game
+	var p Player
+	if got := call(t, srv, http.MethodPost, "/api/player/teleport", `{"position": [4, 12.5, -8]}`, &p); got != http.StatusOK {
+		t.Fatalf("status %d, want 200", got)
+	}
+	want := mgl32.Vec3{4, 12.5, -8}
+	if p.Position != Vec3(want) || g.Player.Position != want {
+		t.Errorf("player at %v (reported %v), want %v", g.Player.Position, p.Position, want)
+	}
+	if g.Player.Velocity != (mgl32.Vec3{}) {
+		t.Errorf("velocity %v after teleport, want zero", g.Player.Velocity)
+	}
+	for _, body := range []string{"", `{}`, `{"position": [1, 2]}`} {
+		if got := call(t, srv, http.MethodPost, "/api/player/teleport", body, nil); got != http.StatusBadRequest {
+			t.Errorf("teleport with body %q: status %d, want 400", body, got)
+		}
+	}
+	if got := call(t, srv, http.MethodGet, "/api/player", "", &p); got != http.StatusOK || p.Position != Vec3(want) {
+		t.Errorf("GET /api/player = %d, %v", got, p.Position)
+	}
+}
+
+func TestChunks(t *testing.T) {
+	srv, _ := newTestServer(t, true)
+	var c Chunks
+	if got := call(t, srv, http.MethodGet, "/api/chunks", "", &c); got != http.StatusOK {
+		t.Fatalf("status %d, want 200", got)
+	}
+	want := [][2]int{{-1, -1}, {-1, 0}, {-1, 1}, {0, -1}, {0, 0}, {0, 1}, {1, -1}, {1, 0}, {1, 1}}
+	if c.Radius != 1 || !slices.Equal(c.Chunks, want) {
+		t.Errorf("got radius %d chunks %v, want 1 %v", c.Radius, c.Chunks, want)
+	}
+}
+
+func TestEntities(t *testing.T) {
+	srv, _ := newTestServer(t, true)
+	var list []Entity
+	if got := call(t, srv, http.MethodGet, "/api/entities", "", &list); got != http.StatusOK {
+		t.Fatalf("status %d, want 200", got)
+	}
+	want := []Entity{
+		{ID: 1, Type: "pig", Position: Vec3{1, 8, 2}, Yaw: 90},
+		{ID: 2, Type: "pig", Position: Vec3{-3, 9, 4}, Yaw: 90},
+	}
+	if !slices.Equal(list, want) {
+		t.Errorf("got %+v, want %+v", list, want)
+	}
+	if got := call(t, srv, http.MethodGet, "/api/stats", "", nil); got != http.StatusNotFound {
+		t.Errorf("stats without a player: status %d, want 404", got)
+	}
+}
+
+func TestNoGameLoop(t *testing.T) {
+	old := jobTimeout
+	jobTimeout = 50 * time.Millisecond
+	t.Cleanup(func() { jobTimeout = old })
+	srv, s := newTestServer(t, false)
+	g := s.Game
+	before := g.World.GetBlock(0, 5, 0)
+	for _, tt := range []struct{ method, path, body string }{
+		{http.MethodGet, "/api/player", ""},
+		{http.MethodGet, "/api/chunks", ""},
+		{http.MethodPut, "/api/blocks/0/5/0", `{"block": "air"}`},
+	} {
+		if got := call(t, srv, tt.method, tt.path, tt.body, nil); got != http.StatusServiceUnavailable {
+			t.Errorf("%s %s: status %d, want 503", tt.method, tt.path, got)
+		}
+	}
+	if id := g.World.GetBlock(0, 5, 0); id != before {
+		t.Error("a timed out request changed the world")
+	}
+}
+
+func TestAbandonedJobsAreSkipped(t *testing.T) {
+	old := jobTimeout
+	jobTimeout = 50 * time.Millisecond
+	t.Cleanup(func() { jobTimeout = old })
+	srv, s := newTestServer(t, false)
+	g := s.Game
+	before := g.World.GetBlock(0, 5, 0)
+	if got := call(t, srv, http.MethodPut, "/api/blocks/0/5/0", `{"block": "air"}`, nil); got != http.StatusServiceUnavailable {
+		t.Fatalf("status %d, want 503", got)
+	}
+	if got := call(t, srv, http.MethodPost, "/api/player/teleport", `{"position": [4, 12, -8]}`, nil); got != http.StatusServiceUnavailable {
+		t.Fatalf("status %d, want 503", got)
+	}
+
+	// The loop catches up after both requests gave up
+	s.Process()
+	if id := g.World.GetBlock(0, 5, 0); id != before {
+		t.Error("a block change that reported 503 was applied later")
+	}
+	if g.Player.Position != (mgl32.Vec3{0, 10, 0}) {
+		t.Errorf("a teleport that reported 503 moved the player to %v", g.Player.Position)
+	}
+}
@@ -0,0 +1,212 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"something/block"
+	"something/world"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Vec3 is a position as [x, y, z].
+type Vec3 [3]float32
+
+// Player is the response of GET /api/player.
+type Player struct {
+	Position Vec3 `json:"position"`
+	Velocity Vec3 `json:"velocity"`
+	OnGround bool `json:"on_ground"`
+}
+
+// Chunks is the response of GET /api/chunks.
+type Chunks struct {
+	Radius int      `json:"radius"`
+	Chunks [][2]int `json:"chunks"` // Chunk coordinates, sorted
+}
+
+// Block is one block, as returned by the block endpoints.
+type Block struct {
+	X     int    `json:"x"`
+	Y     int    `json:"y"`
+	Z     int    `json:"z"`
+	Block string `json:"block"`
+}
+
+// Entity is one entry of GET /api/entities.
+type Entity struct {
+	ID       uint64  `json:"id"`
+	Type     string  `json:"type"`
+	Position Vec3    `json:"position"`
+	Yaw      float32 `json:"yaw"`
+}
+
+// run executes fn on the game loop, writing a 503 if it does not respond.
+// It reports whether fn ran.
+func (s *Server) run(w http.ResponseWriter, r *http.Request, fn func()) bool {
+	if err := s.do(r.Context(), fn); err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return false
+	}
+	return true
+}
+
+func (s *Server) getPlayer(w http.ResponseWriter, r *http.Request) {
+	if s.Game.Player == nil {
+		writeError(w, http.StatusNotFound, "no player")
+		return
+	}
+	var p Player
+	if s.run(w, r, func() {
+		body := s.Game.Player
+		p = Player{Position: Vec3(body.Position), Velocity: Vec3(body.Velocity), OnGround: body.OnGround}
+	}) {
+		writeJSON(w, http.StatusOK, p)
+	}
+}
+
+func (s *Server) teleport(w http.ResponseWriter, r *http.Request) {
+	if s.Game.Player == nil {
+		writeError(w, http.StatusNotFound, "no player")
+		return
+	}
+	var req struct {
+		Position []float32 `json:"position"` // A slice, so short arrays are rejected rather than zero-filled
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Position) != 3 {
+		writeError(w, http.StatusBadRequest, `body must be {"position": [x, y, z]}`)
+		return
+	}
+	pos := mgl32.Vec3{req.Position[0], req.Position[1], req.Position[2]}
+	var p Player
+	if s.run(w, r, func() {
+		body := s.Game.Player
+		body.Position = pos
+		body.Velocity = mgl32.Vec3{}
+		p = Player{Position: Vec3(body.Position), OnGround: body.OnGround}
+	}) {
+		writeJSON(w, http.StatusOK, p)
+	}
+}
+
+func (s *Server) listChunks(w http.ResponseWriter, r *http.Request) {
+	var c Chunks
+	if s.run(w, r, func() {
+		c.Radius = s.Game.World.ChunkRadius
+		c.Chunks = make([][2]int, 0, len(s.Game.World.Chunks))
+		for key := range s.Game.World.Chunks {
+			c.Chunks = append(c.Chunks, key)
+		}
+	}) {
+		sort.Slice(c.Chunks, func(i, j int) bool {
+			if c.Chunks[i][0] != c.Chunks[j][0] {
+				return c.Chunks[i][0] < c.Chunks[j][0]
+			}
+			return c.Chunks[i][1] < c.Chunks[j][1]
+		})
+		writeJSON(w, http.StatusOK, c)
+	}
+}
+
+// blockPos parses the {x}/{y}/{z} path segments, writing a 400 if invalid.
+func blockPos(w http.ResponseWriter, r *http.Request) (Block, bool) {
+	var b Block
+	for _, p := range []struct {
+		name string
+		v    *int
+	}{{"x", &b.X}, {"y", &b.Y}, {"z", &b.Z}} {
+		n, err := strconv.Atoi(r.PathValue(p.name))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid "+p.name+" coordinate")
+			return b, false
+		}
+		*p.v = n
+	}
+	if b.Y < 0 || b.Y >= world.ChunkSize {
+		writeError(w, http.StatusBadRequest, "y is outside the world")
+		return b, false
+	}
+	return b, true
+}
+
+func (s *Server) getBlock(w http.ResponseWriter, r *http.Request) {
+	b, ok := blockPos(w, r)
+	if !ok {
+		return
+	}
+	loaded := false
+	if !s.run(w, r, func() {
+		if loaded = s.Game.World.Loaded(b.X, b.Z); loaded {
+			b.Block = block.Names[s.Game.World.GetBlock(b.X, b.Y, b.Z)]
+		}
+	}) {
+		return
+	}
+	if !loaded {
+		writeError(w, http.StatusNotFound, "chunk not loaded")
+		return
+	}
+	writeJSON(w, http.StatusOK, b)
+}
+
+func (s *Server) setBlock(w http.ResponseWriter, r *http.Request) {
+	b, ok := blockPos(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Block string `json:"block"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, `body must be {"block": "<name>"}`)
+		return
+	}
+	id, ok := block.ByName(req.Block)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "unknown block "+strconv.Quote(req.Block))
+		return
+	}
+	set := false
+	if !s.run(w, r, func() { set = s.Game.World.SetBlock(b.X, b.Y, b.Z, id) }) {
+		return
+	}
+	if !set {
+		writeError(w, http.StatusNotFound, "chunk not loaded")
+		return
+	}
+	b.Block = req.Block
+	writeJSON(w, http.StatusOK, b)
+}
+
+func (s *Server) listEntities(w http.ResponseWriter, r *http.Request) {
+	list := []Entity{}
+	if s.Game.Entities == nil {
+		writeJSON(w, http.StatusOK, list)
+		return
+	}
+	if s.run(w, r, func() {
+		m := s.Game.Entities
+		for _, id := range m.IDs() {
+			e := Entity{ID: uint64(id), Type: m.Types[id]}
+			if t, ok := m.Transforms[id]; ok {
+				e.Position, e.Yaw = Vec3(t.Position), t.Yaw
+			}
+			list = append(list, e)
+		}
+	}) {
+		writeJSON(w, http.StatusOK, list)
+	}
+}
+
+// getStats needs no job; Stats guards itself.
+func (s *Server) getStats(w http.ResponseWriter, r *http.Request) {
+	if s.Stats == nil {
+		writeError(w, http.StatusNotFound, "no stats")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	s.Stats.WriteJSON(w)
+}
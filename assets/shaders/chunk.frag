@@ -0,0 +1,34 @@
+#version 460 core
+in vec2 TexCoord;
+in vec3 Normal;
+in vec3 FragPos;
+in float ViewDepth;
+out vec4 fragColor;
+uniform sampler2D texture1;
+uniform vec3 lightDir;   // Direction the sun or moon light travels
+uniform vec3 lightColor;
+uniform float ambient;
+uniform vec3 viewPos;
+uniform bool showNormals;
+uniform vec4 tint; // rgb blended over the result by a
+
+#include "include/shadow.glsl"
+#include "include/fog.glsl"
+
+void main() {
+    vec3 norm = normalize(Normal);
+    if (showNormals) {
+        fragColor = vec4(mix(norm * 0.5 + 0.5, tint.rgb, tint.a), 1.0);
+        return;
+    }
+    vec3 lightDirection = normalize(-lightDir);
+    float diff = max(dot(norm, lightDirection), 0.0);
+    vec3 albedo = texture(texture1, TexCoord).rgb;
+    int cascade = cascadeIndex(ViewDepth);
+    vec3 result = ambient * albedo + diff * shadowLight(cascade, FragPos, norm, lightDirection) * lightColor * albedo;
+    if (showCascades && cascade >= 0) {
+        result *= cascadeColors[cascade];
+    }
+    result = mix(result, tint.rgb, tint.a);
+    fragColor = vec4(applyFog(result, FragPos, viewPos), 1.0);
+}
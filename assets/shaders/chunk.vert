@@ -0,0 +1,19 @@
+#version 460 core
+layout(location = 0) in vec3 position;
+layout(location = 1) in vec2 texCoord;
+layout(location = 2) in vec3 normal;
+out vec2 TexCoord;
+out vec3 Normal;
+out vec3 FragPos;
+out float ViewDepth;
+uniform mat4 model;
+uniform mat4 view;
+uniform mat4 projection;
+void main() {
+    FragPos = vec3(model * vec4(position, 1.0));
+    Normal = mat3(transpose(inverse(model))) * normal;
+    vec4 viewSpace = view * vec4(FragPos, 1.0);
+    ViewDepth = -viewSpace.z;
+    gl_Position = projection * viewSpace;
+    TexCoord = texCoord;
+}
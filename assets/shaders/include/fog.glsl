@@ -0,0 +1,8 @@
+// Distance fog toward the sky's horizon colour.
+uniform vec3 fogColor;
+uniform vec2 fogRange; // Horizontal distance where fog starts and is complete
+
+vec3 applyFog(vec3 color, vec3 worldPos, vec3 viewPos) {
+    float fog = smoothstep(fogRange.x, fogRange.y, length(worldPos.xz - viewPos.xz));
+    return mix(color, fogColor, fog);
+}
@@ -0,0 +1,42 @@
+// Cascaded shadow map lookup. The uniforms are set by shadow.Map.Bind.
+uniform sampler2DArrayShadow shadowMap;
+uniform int cascadeCount; // 0 when shadows are off
+uniform mat4 lightSpace[4];
+uniform float cascadeSplits[4];
+uniform int pcfRadius;
+uniform bool showCascades;
+
+const vec3 cascadeColors[4] = vec3[](vec3(1, 0.3, 0.3), vec3(0.3, 1, 0.3), vec3(0.3, 0.5, 1), vec3(1, 1, 0.3));
+
+// cascadeIndex picks the cascade covering a view depth, or -1 past the last.
+int cascadeIndex(float viewDepth) {
+    for (int i = 0; i < cascadeCount; i++) {
+        if (viewDepth < cascadeSplits[i]) {
+            return i;
+        }
+    }
+    return -1;
+}
+
+// shadowLight returns how lit a point is, 0 to 1, averaging a
+// (2r+1)^2 grid of hardware-compared taps.
+float shadowLight(int cascade, vec3 worldPos, vec3 norm, vec3 toLight) {
+    if (cascade < 0 || dot(norm, toLight) <= 0.0) {
+        return 1.0;
+    }
+    // Offsetting along the normal keeps surfaces from shadowing themselves
+    vec4 ls = lightSpace[cascade] * vec4(worldPos + norm * 0.04 * float(cascade + 1), 1.0);
+    vec3 p = ls.xyz / ls.w * 0.5 + 0.5;
+    if (p.z > 1.0) {
+        return 1.0;
+    }
+    vec2 texel = 1.0 / vec2(textureSize(shadowMap, 0).xy);
+    float lit = 0.0;
+    for (int x = -pcfRadius; x <= pcfRadius; x++) {
+        for (int y = -pcfRadius; y <= pcfRadius; y++) {
+            lit += texture(shadowMap, vec4(p.xy + vec2(x, y) * texel, cascade, p.z));
+        }
+    }
+    float taps = float((2 * pcfRadius + 1) * (2 * pcfRadius + 1));
+    return lit / taps;
+}
@@ -0,0 +1,6 @@
+#version 460 core
+in vec4 Color;
+out vec4 fragColor;
+void main() {
+    fragColor = Color;
+}
@@ -0,0 +1,9 @@
+#version 460 core
+layout(location = 0) in vec3 pos;
+layout(location = 1) in vec4 color;
+out vec4 Color;
+uniform mat4 viewProjection;
+void main() {
+    gl_Position = viewProjection * vec4(pos, 1.0);
+    Color = color;
+}
@@ -0,0 +1,15 @@
+#version 460 core
+in vec2 TexCoord;
+out vec4 fragColor;
+uniform sampler2D modelTexture;
+uniform vec3 partColor;
+uniform bool useTexture;
+void main() {
+    if (useTexture) {
+        vec4 color = texture(modelTexture, TexCoord);
+        if (color.a < 0.1) discard;
+        fragColor = color;
+    } else {
+        fragColor = vec4(partColor, 1.0);
+    }
+}
@@ -0,0 +1,3 @@
+#version 460 core
+void main() {
+}
@@ -0,0 +1,25 @@
+#version 460 core
+in vec2 ndc;
+out vec4 fragColor;
+uniform mat4 inverseViewProjection;
+uniform vec3 zenith;
+uniform vec3 horizon;
+uniform vec3 sunDir;
+uniform vec3 moonDir;
+void main() {
+    vec4 far = inverseViewProjection * vec4(ndc, 1.0, 1.0);
+    vec3 dir = normalize(far.xyz / far.w);
+    vec3 color = mix(horizon, zenith, pow(clamp(dir.y, 0.0, 1.0), 0.6));
+    if (dir.y < 0.0) {
+        color = horizon * (1.0 + dir.y * 0.3);
+    }
+    float sun = dot(dir, sunDir);
+    color += vec3(1.0, 0.8, 0.5) * pow(max(sun, 0.0), 64.0) * 0.4;
+    if (sun > 0.9995) {
+        color = vec3(1.0, 0.95, 0.8);
+    }
+    if (dot(dir, moonDir) > 0.9997) {
+        color = vec3(0.85, 0.88, 0.95);
+    }
+    fragColor = vec4(color, 1.0);
+}
@@ -0,0 +1,7 @@
+#version 460 core
+out vec2 ndc;
+void main() {
+    // One triangle covering the screen, from the vertex index alone
+    ndc = vec2((gl_VertexID << 1) & 2, gl_VertexID & 2) * 2.0 - 1.0;
+    gl_Position = vec4(ndc, 1.0, 1.0);
+}
@@ -0,0 +1,10 @@
+#version 460 core
+in vec2 TexCoord;
+in vec4 Color;
+out vec4 fragColor;
+uniform sampler2D atlas;
+void main() {
+    float alpha = texture(atlas, TexCoord).r;
+    if (alpha < 0.01) discard;
+    fragColor = vec4(Color.rgb, Color.a * alpha);
+}
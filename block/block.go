@@ -63,3 +63,21 @@ var Blocks = map[BlockID]Block{
 	BlockDirt:  DirtBlock{id: BlockDirt},
 	BlockStone: StoneBlock{id: BlockStone},
 }
+
+// Names maps BlockIDs to the lowercase names used in data files.
+var Names = map[BlockID]string{
+	BlockAir:   "air",
+	BlockGrass: "grass",
+	BlockDirt:  "dirt",
+	BlockStone: "stone",
+}
+
+// ByName looks up a block by its data file name.
+func ByName(name string) (BlockID, bool) {
+	for id, n := range Names {
+		if n == name {
+			return id, true
+		}
+	}
+	return BlockAir, false
+}
@@ -0,0 +1,196 @@
+// Package capture reads rendered frames back from GL and writes them as
+// PNG screenshots and six-face panoramas.
+package capture
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Dir is where captures are written by default.
+const Dir = "screenshots"
+
+// ReadPixels copies the bottom-left width by height pixels of the bound
+// read framebuffer into an image, flipping it so row 0 is the top.
+func ReadPixels(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.ReadPixels(0, 0, int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+	stride := img.Stride
+	row := make([]byte, stride)
+	for y := 0; y < height/2; y++ {
+		top, bottom := img.Pix[y*stride:(y+1)*stride], img.Pix[(height-1-y)*stride:(height-y)*stride]
+		copy(row, top)
+		copy(top, bottom)
+		copy(bottom, row)
+	}
+	// The default framebuffer may carry alpha from blending; captures are opaque
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+	return img
+}
+
+// Save writes an image as a PNG, creating parent directories. The file is
+// written under a temporary name and renamed, so readers never see a
+// partial image.
+func Save(img image.Image, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create capture directory: %w", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create capture: %w", err)
+	}
+	defer os.Remove(f.Name()) // No-op once renamed
+	if err := f.Chmod(0o644); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to create capture: %w", err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to encode capture: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write capture: %w", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("failed to write capture: %w", err)
+	}
+	return nil
+}
+
+// TimestampPath returns an unused path in dir named after t, adding a
+// counter when several captures land in the same second.
+func TimestampPath(dir string, t time.Time, ext string) string {
+	base := t.Format("2006-01-02_15.04.05")
+	path := filepath.Join(dir, base+ext)
+	for i := 2; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s_%d%s", base, i, ext))
+	}
+}
+
+// Target is an offscreen framebuffer with colour and depth, for captures
+// at a size other than the window's.
+type Target struct {
+	Width, Height int
+	fbo           uint32
+	color, depth  uint32
+}
+
+func NewTarget(width, height int) (*Target, error) {
+	t := &Target{Width: width, Height: height}
+	gl.GenFramebuffers(1, &t.fbo)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, t.fbo)
+	gl.GenRenderbuffers(1, &t.color)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, t.color)
+	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.RGBA8, int32(width), int32(height))
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.RENDERBUFFER, t.color)
+	gl.GenRenderbuffers(1, &t.depth)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, t.depth)
+	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH_COMPONENT24, int32(width), int32(height))
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, t.depth)
+	status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	if status != gl.FRAMEBUFFER_COMPLETE {
+		t.Cleanup()
+		return nil, fmt.Errorf("capture framebuffer incomplete: 0x%x", status)
+	}
+	return t, nil
+}
+
+// Render draws into the target with its viewport and returns the result.
+// The previous framebuffer binding and viewport are restored.
+func (t *Target) Render(draw func()) *image.RGBA {
+	var viewport [4]int32
+	gl.GetIntegerv(gl.VIEWPORT, &viewport[0])
+	gl.BindFramebuffer(gl.FRAMEBUFFER, t.fbo)
+	gl.Viewport(0, 0, int32(t.Width), int32(t.Height))
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	draw()
+	img := ReadPixels(t.Width, t.Height)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	gl.Viewport(viewport[0], viewport[1], viewport[2], viewport[3])
+	return img
+}
+
+func (t *Target) Cleanup() {
+	gl.DeleteFramebuffers(1, &t.fbo)
+	gl.DeleteRenderbuffers(1, &t.color)
+	gl.DeleteRenderbuffers(1, &t.depth)
+}
+
+// Face is one view of a cubemap.
+type Face struct {
+	Name string
+	Dir  mgl32.Vec3
+	Up   mgl32.Vec3
+}
+
+// Faces are the six cube views. Up vectors are chosen so the faces join
+// in the Cross layout rather than the GL cubemap convention.
+var Faces = [6]Face{
+	{"px", mgl32.Vec3{1, 0, 0}, mgl32.Vec3{0, 1, 0}},
+	{"nx", mgl32.Vec3{-1, 0, 0}, mgl32.Vec3{0, 1, 0}},
+	{"py", mgl32.Vec3{0, 1, 0}, mgl32.Vec3{0, 0, 1}},
+	{"ny", mgl32.Vec3{0, -1, 0}, mgl32.Vec3{0, 0, -1}},
+	{"pz", mgl32.Vec3{0, 0, 1}, mgl32.Vec3{0, 1, 0}},
+	{"nz", mgl32.Vec3{0, 0, -1}, mgl32.Vec3{0, 1, 0}},
+}
+
+// crossCells places each face in a 4x3 grid: the horizontal views in a
+// row from -X, with +Y above and -Y below the -Z face.
+var crossCells = map[string]image.Point{
+	"py": {1, 0},
+	"nx": {0, 1}, "nz": {1, 1}, "px": {2, 1}, "pz": {3, 1},
+	"ny": {1, 2},
+}
+
+// Cubemap renders the six faces from eye as square images. render draws
+// the scene for a view with the given vertical field of view (radians)
+// and aspect ratio.
+func Cubemap(size int, eye mgl32.Vec3, render func(view mgl32.Mat4, fovY, aspect float32)) (map[string]*image.RGBA, error) {
+	target, err := NewTarget(size, size)
+	if err != nil {
+		return nil, err
+	}
+	defer target.Cleanup()
+	faces := make(map[string]*image.RGBA, len(Faces))
+	for _, f := range Faces {
+		view := mgl32.LookAtV(eye, eye.Add(f.Dir), f.Up)
+		faces[f.Name] = target.Render(func() { render(view, mgl32.DegToRad(90), 1) })
+	}
+	return faces, nil
+}
+
+// Cross lays the faces out as an unfolded cube.
+func Cross(faces map[string]*image.RGBA) *image.RGBA {
+	size := faces["px"].Bounds().Dx()
+	out := image.NewRGBA(image.Rect(0, 0, 4*size, 3*size))
+	for name, cell := range crossCells {
+		at := image.Rect(cell.X*size, cell.Y*size, (cell.X+1)*size, (cell.Y+1)*size)
+		draw.Draw(out, at, faces[name], image.Point{}, draw.Src)
+	}
+	return out
+}
+
+// SaveCubemap writes each face as <dir>/<name>.png plus cross.png.
+func SaveCubemap(dir string, faces map[string]*image.RGBA) error {
+	for name, img := range faces {
+		if err := Save(img, filepath.Join(dir, name+".png")); err != nil {
+			return err
+		}
+	}
+	return Save(Cross(faces), filepath.Join(dir, "cross.png"))
+}
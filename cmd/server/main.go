@@ -0,0 +1,110 @@
+// Command server runs a world without a window and executes developer
+// console commands read from standard input, one per line. With -admin it
+// also serves the admin API.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"something/admin"
+	"something/commands"
+	"something/console"
+	"something/entities"
+	"something/world"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var (
+	seed   = flag.Int64("seed", world.DefaultSeed, "terrain seed")
+	radius = flag.Int("radius", 2, "chunk radius kept loaded around the origin")
+
+	adminAddr  = flag.String("admin", "", "serve the admin API on this address, e.g. "+admin.DefaultAddr)
+	adminToken = flag.String("admin-token", "", "bearer token for the admin API; empty generates one")
+)
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		log.Fatalf("server error: %v", err)
+	}
+}
+
+func run() error {
+	gameWorld := &world.World{
+		Chunks:      make(map[[2]int]*world.Chunk),
+		ChunkRadius: *radius,
+		Seed:        *seed,
+		Headless:    true,
+	}
+	gameWorld.UpdateChunks(mgl32.Vec3{})
+
+	entityManager := entities.NewManager(gameWorld)
+	mobs, err := entities.LoadMobDefs("assets/entities")
+	if err != nil {
+		return err
+	}
+	for _, def := range mobs {
+		entities.RegisterMob(entityManager, def, nil) // No models without a renderer
+	}
+
+	game := &commands.Game{World: gameWorld, Entities: entityManager}
+	registry := console.NewRegistry()
+	if err := commands.Register(registry, game); err != nil {
+		return err
+	}
+
+	// Admin requests run on this goroutine between commands; a nil channel
+	// never delivers, so without -admin only stdin is read
+	var adminJobs <-chan func()
+	if *adminAddr != "" {
+		srv, addr, err := admin.Start(*adminAddr, *adminToken, game, nil)
+		if err != nil {
+			return err
+		}
+		defer srv.Close()
+		adminJobs = srv.Jobs()
+		log.Printf("admin API on http://%s (token %s)", addr, srv.Token)
+	}
+
+	lines := make(chan string)
+	var scanErr error
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		scanErr = scanner.Err()
+		close(lines)
+	}()
+	for {
+		var line string
+		select {
+		case job := <-adminJobs:
+			job()
+			continue
+		case l, ok := <-lines:
+			if !ok {
+				if adminJobs == nil || scanErr != nil {
+					return scanErr
+				}
+				lines = nil // Keep serving the admin API after stdin ends
+				continue
+			}
+			line = l
+		}
+		out, err := registry.Execute(line)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			continue
+		}
+		if out != "" {
+			fmt.Println(out)
+		}
+		gameWorld.UpdateChunks(mgl32.Vec3{}) // Apply radius changes
+	}
+}
@@ -0,0 +1,105 @@
+// Command worldmap renders a top-down map of a seed's generated terrain,
+// either as one PNG or as a directory of z/x/y tiles for a zoomable viewer.
+// It needs no window or GPU. The web server runs it with -tile to fill its
+// tile cache on demand.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"something/capture"
+	"something/world"
+	"something/worldmap"
+)
+
+var (
+	seed    = flag.Int64("seed", world.DefaultSeed, "terrain seed")
+	saveDir = flag.String("world", "", "world save directory to read the seed from, overriding -seed")
+	area    = flag.String("area", "-256,-256,256,256", "block area as x1,z1,x2,z2")
+	mode    = flag.String("mode", string(worldmap.ByBiome), "colour by block or biome")
+	scale   = flag.Int("scale", 1, "blocks per pixel for -o")
+	out     = flag.String("o", "map.png", "PNG to write")
+	tiles   = flag.String("tiles", "", "write z/x/y tiles to this directory instead of -o")
+	minZoom = flag.Int("minzoom", 0, "lowest zoom level for -tiles")
+	maxZoom = flag.Int("maxzoom", worldmap.MaxZoom, "highest zoom level for -tiles")
+	tile    = flag.String("tile", "", "with -tiles, write only this tile, given as z/x/y")
+)
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		log.Fatalf("worldmap: %v", err)
+	}
+}
+
+func run() error {
+	rect, err := parseArea(*area)
+	if err != nil {
+		return err
+	}
+	m, err := worldmap.ParseMode(*mode)
+	if err != nil {
+		return err
+	}
+	info := world.Info{Seed: *seed}
+	if *saveDir != "" {
+		if err := world.LoadInfo(*saveDir, &info); err != nil {
+			return err
+		}
+	}
+	r := &worldmap.Renderer{Source: worldmap.NewGenerated(info.Seed), Mode: m}
+	start := time.Now()
+	if *tile != "" {
+		if *tiles == "" {
+			return fmt.Errorf("-tile needs -tiles")
+		}
+		var z, x, y int
+		if _, err := fmt.Sscanf(*tile, "%d/%d/%d", &z, &x, &y); err != nil {
+			return fmt.Errorf("tile %q: want z/x/y", *tile)
+		}
+		return r.SaveTile(*tiles, z, x, y)
+	}
+	if *tiles != "" {
+		n, err := r.ExportTiles(*tiles, rect, *minZoom, *maxZoom)
+		if err != nil {
+			return err
+		}
+		log.Printf("wrote %d tiles to %s in %v", n, *tiles, time.Since(start).Round(time.Millisecond))
+		return nil
+	}
+	if *scale < 1 {
+		return fmt.Errorf("scale must be at least 1")
+	}
+	if err := capture.Save(r.Render(rect, *scale), *out); err != nil {
+		return err
+	}
+	log.Printf("wrote %s in %v", *out, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// parseArea reads "x1,z1,x2,z2"; the corners may be in either order.
+func parseArea(s string) (image.Rectangle, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return image.Rectangle{}, fmt.Errorf("area %q: want x1,z1,x2,z2", s)
+	}
+	var v [4]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return image.Rectangle{}, fmt.Errorf("area %q: invalid number %q", s, p)
+		}
+		v[i] = n
+	}
+	rect := image.Rect(v[0], v[1], v[2], v[3])
+	if rect.Empty() {
+		return rect, fmt.Errorf("area %q is empty", s)
+	}
+	return rect, nil
+}
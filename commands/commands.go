@@ -0,0 +1,331 @@
+// Package commands registers the game's developer commands on a console
+// registry. It does not depend on the window, so a headless server can use
+// the same commands.
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"something/block"
+	"something/console"
+	"something/entities"
+	"something/physics"
+	"something/shadow"
+	"something/world"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// maxFillVolume bounds fill so a typo cannot stall the game.
+const maxFillVolume = 32768
+
+// Game is what the commands act on. Optional fields may be nil; commands
+// that need them report an error instead.
+type Game struct {
+	World         *world.World
+	Entities      *entities.Manager
+	Player        *physics.Body // The player's body; nil on a server without a player
+	LookDir       func() mgl32.Vec3
+	ReloadShaders func() error // nil without a renderer
+	Shadows       *shadow.Map  // nil without a renderer
+}
+
+var errNoPlayer = errors.New("no player")
+
+// Register adds every command to r.
+func Register(r *console.Registry, g *Game) error {
+	cmds := []console.Command{
+		{Name: "tp", Usage: "<x> <y> <z>", Help: "Teleport the player; ~ is relative", MinArgs: 3, MaxArgs: 3, Run: g.tp},
+		{Name: "setblock", Usage: "<x> <y> <z> <block>", Help: "Set one block", MinArgs: 4, MaxArgs: 4, Run: g.setblock, Complete: completeBlock(3)},
+		{Name: "fill", Usage: "<x1> <y1> <z1> <x2> <y2> <z2> <block>", Help: "Set every block in a box", MinArgs: 7, MaxArgs: 7, Run: g.fill, Complete: completeBlock(6)},
+		{Name: "seed", Help: "Show the world seed", Run: g.seed},
+		{Name: "time", Usage: "[set <hour|name> | add <hours>]", Help: "Show or change the time of day", MaxArgs: 2, Run: g.time, Complete: completeTime},
+		{Name: "spawn", Usage: "<type> [x y z]", Help: "Spawn an entity in front of the player or at a position", MinArgs: 1, MaxArgs: 4, Run: g.spawn, Complete: g.completeSpawn},
+		{Name: "radius", Usage: "[chunks]", Help: "Show or set the chunk load radius", MaxArgs: 1, Run: g.radius},
+		{Name: "wireframe", Usage: "[on|off]", Help: "Toggle wireframe terrain", MaxArgs: 1, Run: g.wireframe, Complete: fixed("on", "off")},
+		{Name: "shadows", Usage: "[off|low|medium|high]", Help: "Show or set shadow quality", MaxArgs: 1, Run: g.shadows, Complete: g.completeShadows},
+		{Name: "reload", Usage: "shaders", Help: "Recompile shaders", MinArgs: 1, MaxArgs: 1, Run: g.reload, Complete: fixed("shaders")},
+	}
+	for _, c := range cmds {
+		if err := r.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *Game) origin() mgl32.Vec3 {
+	if g.Player == nil {
+		return mgl32.Vec3{}
+	}
+	return g.Player.Position
+}
+
+// coords parses three coordinates relative to the player.
+func (g *Game) coords(args []string) (mgl32.Vec3, error) {
+	origin := g.origin()
+	var pos mgl32.Vec3
+	for i := 0; i < 3; i++ {
+		v, err := console.Coord(args[i], float64(origin[i]))
+		if err != nil {
+			return pos, err
+		}
+		pos[i] = float32(v)
+	}
+	return pos, nil
+}
+
+// blockCoords parses three coordinates and floors them to a block.
+func (g *Game) blockCoords(args []string) ([3]int, error) {
+	pos, err := g.coords(args)
+	if err != nil {
+		return [3]int{}, err
+	}
+	return [3]int{int(math.Floor(float64(pos[0]))), int(math.Floor(float64(pos[1]))), int(math.Floor(float64(pos[2])))}, nil
+}
+
+func (g *Game) tp(args []string) (string, error) {
+	if g.Player == nil {
+		return "", errNoPlayer
+	}
+	pos, err := g.coords(args)
+	if err != nil {
+		return "", err
+	}
+	g.Player.Position = pos
+	g.Player.Velocity = mgl32.Vec3{}
+	return fmt.Sprintf("Teleported to %.1f %.1f %.1f", pos[0], pos[1], pos[2]), nil
+}
+
+func parseBlock(name string) (block.BlockID, error) {
+	id, ok := block.ByName(name)
+	if !ok {
+		return 0, fmt.Errorf("unknown block %q", name)
+	}
+	return id, nil
+}
+
+func (g *Game) setblock(args []string) (string, error) {
+	pos, err := g.blockCoords(args)
+	if err != nil {
+		return "", err
+	}
+	id, err := parseBlock(args[3])
+	if err != nil {
+		return "", err
+	}
+	if !g.World.SetBlock(pos[0], pos[1], pos[2], id) {
+		return "", fmt.Errorf("%d %d %d is not in a loaded chunk", pos[0], pos[1], pos[2])
+	}
+	return fmt.Sprintf("Set %d %d %d to %s", pos[0], pos[1], pos[2], args[3]), nil
+}
+
+func (g *Game) fill(args []string) (string, error) {
+	from, err := g.blockCoords(args[0:3])
+	if err != nil {
+		return "", err
+	}
+	to, err := g.blockCoords(args[3:6])
+	if err != nil {
+		return "", err
+	}
+	id, err := parseBlock(args[6])
+	if err != nil {
+		return "", err
+	}
+	volume := 1
+	for i := 0; i < 3; i++ {
+		volume *= abs(to[i]-from[i]) + 1
+	}
+	if volume > maxFillVolume {
+		return "", fmt.Errorf("box of %d blocks exceeds the limit of %d", volume, maxFillVolume)
+	}
+	return fmt.Sprintf("Changed %d blocks", g.World.Fill(from, to, id)), nil
+}
+
+func (g *Game) seed(args []string) (string, error) {
+	return fmt.Sprintf("Seed: %d", g.World.Seed), nil
+}
+
+func (g *Game) time(args []string) (string, error) {
+	switch {
+	case len(args) == 0:
+	case len(args) == 2 && args[0] == "set":
+		hour, ok := world.TimesOfDay[args[1]]
+		if !ok {
+			var err error
+			if hour, err = console.Float(args[1], "hour"); err != nil {
+				return "", err
+			}
+		}
+		g.World.SetTimeOfDay(hour)
+	case len(args) == 2 && args[0] == "add":
+		hours, err := console.Float(args[1], "hours")
+		if err != nil {
+			return "", err
+		}
+		g.World.Time = max(0, g.World.Time+hours/24*world.DayLength)
+	default:
+		return "", fmt.Errorf("usage: time [set <hour|name> | add <hours>]")
+	}
+	hour := g.World.TimeOfDay()
+	return fmt.Sprintf("Day %d, %02d:%02d", g.World.Day(), int(hour), int(math.Mod(hour, 1)*60)), nil
+}
+
+func (g *Game) spawn(args []string) (string, error) {
+	var pos mgl32.Vec3
+	switch len(args) {
+	case 1:
+		if g.Player == nil {
+			return "", errNoPlayer
+		}
+		pos = g.Player.Position
+		if g.LookDir != nil {
+			dir := g.LookDir()
+			dir[1] = 0
+			if dir.Len() > 0 {
+				pos = pos.Add(dir.Normalize().Mul(3))
+			}
+		}
+	case 4:
+		var err error
+		if pos, err = g.coords(args[1:]); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("usage: spawn <type> [x y z]")
+	}
+	id, err := g.Entities.Spawn(args[0], entities.Transform{Position: pos})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Spawned %s #%d", args[0], id), nil
+}
+
+func (g *Game) radius(args []string) (string, error) {
+	if len(args) == 1 {
+		r, err := console.Int(args[0], "radius")
+		if err != nil {
+			return "", err
+		}
+		if r < 1 || r > 16 {
+			return "", fmt.Errorf("radius must be between 1 and 16")
+		}
+		g.World.ChunkRadius = r
+	}
+	return "Chunk radius: " + strconv.Itoa(g.World.ChunkRadius), nil
+}
+
+func (g *Game) wireframe(args []string) (string, error) {
+	switch {
+	case len(args) == 0:
+		g.World.Wireframe = !g.World.Wireframe
+	case args[0] == "on" || args[0] == "off":
+		g.World.Wireframe = args[0] == "on"
+	default:
+		return "", fmt.Errorf("usage: wireframe [on|off]")
+	}
+	if g.World.Wireframe {
+		return "Wireframe on", nil
+	}
+	return "Wireframe off", nil
+}
+
+func (g *Game) reload(args []string) (string, error) {
+	if args[0] != "shaders" {
+		return "", fmt.Errorf("usage: reload shaders")
+	}
+	if g.ReloadShaders == nil {
+		return "", errors.New("no renderer to reload")
+	}
+	if err := g.ReloadShaders(); err != nil {
+		return "", err
+	}
+	return "Shaders reloaded", nil
+}
+
+func (g *Game) shadows(args []string) (string, error) {
+	if g.Shadows == nil {
+		return "", errors.New("no renderer for shadows")
+	}
+	if len(args) == 1 {
+		q, err := shadow.ParseQuality(args[0])
+		if err != nil {
+			return "", err
+		}
+		if err := g.Shadows.SetQuality(q); err != nil {
+			return "", err
+		}
+	}
+	return "Shadow quality: " + string(g.Shadows.Quality), nil
+}
+
+func (g *Game) completeShadows(args []string) []string {
+	if len(args) != 1 {
+		return nil
+	}
+	names := make([]string, len(shadow.Qualities))
+	for i, q := range shadow.Qualities {
+		names[i] = string(q)
+	}
+	return names
+}
+
+// completeBlock completes block names at argument index i.
+func completeBlock(i int) func(args []string) []string {
+	return func(args []string) []string {
+		if len(args)-1 != i {
+			return nil
+		}
+		names := make([]string, 0, len(block.Names))
+		for _, name := range block.Names {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names
+	}
+}
+
+func completeTime(args []string) []string {
+	switch len(args) {
+	case 1:
+		return []string{"add", "set"}
+	case 2:
+		if args[0] == "set" {
+			names := make([]string, 0, len(world.TimesOfDay))
+			for name := range world.TimesOfDay {
+				names = append(names, name)
+			}
+			return names
+		}
+	}
+	return nil
+}
+
+func (g *Game) completeSpawn(args []string) []string {
+	if len(args) == 1 {
+		return g.Entities.TypeNames()
+	}
+	return nil
+}
+
+// fixed completes the first argument from a fixed list.
+func fixed(options ...string) func(args []string) []string {
+	return func(args []string) []string {
+		if len(args) == 1 {
+			return options
+		}
+		return nil
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"something/block"
+	"something/console"
+	"something/physics"
+	"something/world"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// newTestGame registers the commands for a headless world holding only
+// chunk 0,0 and a player standing in it.
+func newTestGame(t *testing.T) (*console.Registry, *Game) {
+	t.Helper()
+	w := &world.World{Chunks: make(map[[2]int]*world.Chunk), Headless: true}
+	w.UpdateChunks(mgl32.Vec3{})
+	g := &Game{World: w, Player: &physics.Body{Position: mgl32.Vec3{3.5, 10, 4.5}}}
+	r := console.NewRegistry()
+	if err := Register(r, g); err != nil {
+		t.Fatal(err)
+	}
+	return r, g
+}
+
+func TestSetblock(t *testing.T) {
+	r, g := newTestGame(t)
+	tests := []struct {
+		line    string
+		pos     [3]int
+		want    block.BlockID
+		wantErr string
+	}{
+		{"setblock 1 2 3 stone", [3]int{1, 2, 3}, block.BlockStone, ""},
+		{"setblock ~ ~1 ~-1 dirt", [3]int{3, 11, 3}, block.BlockDirt, ""},
+		{"setblock 1.9 2 3 air", [3]int{1, 2, 3}, block.BlockAir, ""},
+		{"setblock 1 2 3 lava", [3]int{}, 0, `unknown block "lava"`},
+		{"setblock 40 2 3 stone", [3]int{}, 0, "40 2 3 is not in a loaded chunk"},
+		{"setblock 1 99 3 stone", [3]int{}, 0, "1 99 3 is not in a loaded chunk"},
+		{"setblock x 2 3 stone", [3]int{}, 0, `coordinate must be a number, got "x"`},
+		{"setblock 1 2 3", [3]int{}, 0, "usage: setblock <x> <y> <z> <block>"},
+	}
+	for _, tt := range tests {
+		_, err := r.Execute(tt.line)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("%q: error = %v, want %q", tt.line, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.line, err)
+			continue
+		}
+		if got := g.World.GetBlock(tt.pos[0], tt.pos[1], tt.pos[2]); got != tt.want {
+			t.Errorf("%q: block at %v is %d, want %d", tt.line, tt.pos, got, tt.want)
+		}
+	}
+}
+
+func TestFill(t *testing.T) {
+	r, g := newTestGame(t)
+	if _, err := r.Execute("fill 0 0 0 15 15 15 air"); err != nil {
+		t.Fatal(err)
+	}
+	out, err := r.Execute("fill 2 3 4 0 1 2 stone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "Changed 27 blocks" {
+		t.Errorf("fill output %q, want %q", out, "Changed 27 blocks")
+	}
+	for _, p := range [][3]int{{0, 1, 2}, {2, 3, 4}, {1, 2, 3}} {
+		if got := g.World.GetBlock(p[0], p[1], p[2]); got != block.BlockStone {
+			t.Errorf("block at %v is %d, want stone", p, got)
+		}
+	}
+	if got := g.World.GetBlock(3, 3, 4); got != block.BlockAir {
+		t.Errorf("block outside the box changed to %d", got)
+	}
+	// Refilling with the same block changes nothing, and unloaded blocks are skipped
+	if out, _ := r.Execute("fill 0 1 2 2 3 4 stone"); out != "Changed 0 blocks" {
+		t.Errorf("refill output %q", out)
+	}
+	if out, _ := r.Execute("fill 15 0 0 16 0 0 dirt"); out != "Changed 1 blocks" {
+		t.Errorf("fill across the chunk edge output %q", out)
+	}
+	if _, err := r.Execute("fill 0 0 0 100 100 100 air"); err == nil || !strings.Contains(err.Error(), "exceeds the limit") {
+		t.Errorf("oversized fill error = %v", err)
+	}
+}
+
+func TestTimeSet(t *testing.T) {
+	r, g := newTestGame(t)
+	tests := []struct {
+		line    string
+		want    string
+		wantErr bool
+	}{
+		{"time", "Day 0, 00:00", false},
+		{"time set noon", "Day 0, 12:00", false},
+		{"time set 6.5", "Day 1, 06:30", false}, // The clock never runs backwards
+		{"time set sunset", "Day 1, 18:00", false},
+		{"time add 12", "Day 2, 06:00", false},
+		{"time set teatime", "", true},
+		{"time set", "", true},
+	}
+	for _, tt := range tests {
+		got, err := r.Execute(tt.line)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%q = %q, %v; want %q (error %v)", tt.line, got, err, tt.want, tt.wantErr)
+		}
+	}
+	if g.World.Day() != 2 {
+		t.Errorf("world is on day %d, want 2", g.World.Day())
+	}
+}
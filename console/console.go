@@ -0,0 +1,120 @@
+package console
+
+import (
+	"strings"
+)
+
+const (
+	maxOutput  = 200 // Lines of scrollback kept
+	maxHistory = 100 // Submitted lines kept for recall
+)
+
+// Console is the editing state of a command line: input, scrollback and
+// history. Rendering and key handling live with the caller.
+type Console struct {
+	Registry *Registry
+	Open     bool
+	Input    string
+	Output   []string
+
+	history []string
+	recall  int // Index into history while browsing; len(history) when not
+}
+
+func New(r *Registry) *Console {
+	return &Console{Registry: r}
+}
+
+// Toggle opens or closes the console.
+func (c *Console) Toggle() {
+	c.Open = !c.Open
+}
+
+// Print appends lines to the scrollback.
+func (c *Console) Print(text string) {
+	c.Output = append(c.Output, strings.Split(text, "\n")...)
+	if over := len(c.Output) - maxOutput; over > 0 {
+		c.Output = c.Output[over:]
+	}
+}
+
+// Type inserts a character at the end of the input.
+func (c *Console) Type(r rune) {
+	c.Input += string(r)
+}
+
+// Backspace removes the last character of the input.
+func (c *Console) Backspace() {
+	if runes := []rune(c.Input); len(runes) > 0 {
+		c.Input = string(runes[:len(runes)-1])
+	}
+}
+
+// Submit runs the input line, prints its result and records it in history.
+func (c *Console) Submit() {
+	line := strings.TrimSpace(c.Input)
+	c.Input = ""
+	if line == "" {
+		return
+	}
+	if len(c.history) == 0 || c.history[len(c.history)-1] != line {
+		c.history = append(c.history, line)
+		if len(c.history) > maxHistory {
+			c.history = c.history[1:]
+		}
+	}
+	c.recall = len(c.history)
+	c.Print("> " + line)
+	out, err := c.Registry.Execute(line)
+	if err != nil {
+		c.Print("error: " + err.Error())
+		return
+	}
+	if out != "" {
+		c.Print(out)
+	}
+}
+
+// HistoryPrev replaces the input with the previous history entry.
+func (c *Console) HistoryPrev() {
+	if c.recall > 0 {
+		c.recall--
+		c.Input = c.history[c.recall]
+	}
+}
+
+// HistoryNext moves forward in history, ending on an empty line.
+func (c *Console) HistoryNext() {
+	if c.recall < len(c.history) {
+		c.recall++
+	}
+	if c.recall == len(c.history) {
+		c.Input = ""
+		return
+	}
+	c.Input = c.history[c.recall]
+}
+
+// Complete applies tab completion. A single match replaces the input;
+// several extend it to their common prefix and are listed.
+func (c *Console) Complete() {
+	matches := c.Registry.Complete(c.Input)
+	switch len(matches) {
+	case 0:
+		return
+	case 1:
+		c.Input = matches[0] + " "
+		return
+	}
+	prefix := matches[0]
+	for _, m := range matches[1:] {
+		for !strings.HasPrefix(m, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	if len(prefix) > len(c.Input) {
+		c.Input = prefix
+		return
+	}
+	c.Print(strings.Join(matches, "  "))
+}
@@ -0,0 +1,211 @@
+// Package console parses and runs developer commands. The registry has no
+// rendering or window dependencies, so the same commands can be run from
+// the in-game console, a server CLI or tests.
+package console
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Command is a named action with argument checking and completion.
+type Command struct {
+	Name     string
+	Usage    string // Argument synopsis, e.g. "<x> <y> <z>"
+	Help     string
+	MinArgs  int
+	MaxArgs  int                                 // -1 allows any number
+	Run      func(args []string) (string, error) // Returns text to print
+	Complete func(args []string) []string        // Candidates for the last argument; may be nil
+}
+
+// ErrUnknownCommand is returned by Execute for unregistered names.
+var ErrUnknownCommand = errors.New("unknown command")
+
+// Registry holds the available commands.
+type Registry struct {
+	commands map[string]*Command
+}
+
+// NewRegistry creates a registry with the built-in help command.
+func NewRegistry() *Registry {
+	r := &Registry{commands: make(map[string]*Command)}
+	r.Register(Command{
+		Name:    "help",
+		Usage:   "[command]",
+		Help:    "List commands or show a command's usage",
+		MaxArgs: 1,
+		Run:     r.help,
+		Complete: func(args []string) []string {
+			return r.Names()
+		},
+	})
+	return r
+}
+
+// Register adds a command. Names must be unique and contain no spaces.
+func (r *Registry) Register(c Command) error {
+	if c.Name == "" || strings.ContainsAny(c.Name, " \t") {
+		return fmt.Errorf("invalid command name %q", c.Name)
+	}
+	if _, exists := r.commands[c.Name]; exists {
+		return fmt.Errorf("command %q already registered", c.Name)
+	}
+	if c.Run == nil {
+		return fmt.Errorf("command %q has no Run function", c.Name)
+	}
+	r.commands[c.Name] = &c
+	return nil
+}
+
+// Names returns every command name, sorted.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.commands))
+	for name := range r.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Execute parses a line and runs the command it names.
+func (r *Registry) Execute(line string) (string, error) {
+	args, err := Split(line)
+	if err != nil {
+		return "", err
+	}
+	if len(args) == 0 {
+		return "", nil
+	}
+	c, ok := r.commands[args[0]]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownCommand, args[0])
+	}
+	args = args[1:]
+	if len(args) < c.MinArgs || (c.MaxArgs >= 0 && len(args) > c.MaxArgs) {
+		return "", fmt.Errorf("usage: %s", strings.TrimSpace(c.Name+" "+c.Usage))
+	}
+	return c.Run(args)
+}
+
+// Complete returns the possible completions of a partially typed line as
+// whole lines, sorted.
+func (r *Registry) Complete(line string) []string {
+	args, err := Split(line)
+	if err != nil {
+		return nil
+	}
+	if line == "" || strings.HasSuffix(line, " ") {
+		args = append(args, "") // Completing a new, empty argument
+	}
+	if len(args) == 1 {
+		return withPrefix(r.Names(), args[0], "")
+	}
+	c, ok := r.commands[args[0]]
+	if !ok || c.Complete == nil {
+		return nil
+	}
+	last := len(args) - 1
+	prefix := strings.Join(args[:last], " ") + " "
+	return withPrefix(c.Complete(args[1:]), args[last], prefix)
+}
+
+func (r *Registry) help(args []string) (string, error) {
+	if len(args) == 1 {
+		c, ok := r.commands[args[0]]
+		if !ok {
+			return "", fmt.Errorf("%w: %s", ErrUnknownCommand, args[0])
+		}
+		return c.synopsis(), nil
+	}
+	var lines []string
+	for _, name := range r.Names() {
+		c := r.commands[name]
+		lines = append(lines, c.synopsis())
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// synopsis formats a command for help output.
+func (c *Command) synopsis() string {
+	if c.Usage == "" {
+		return fmt.Sprintf("%s - %s", c.Name, c.Help)
+	}
+	return fmt.Sprintf("%s %s - %s", c.Name, c.Usage, c.Help)
+}
+
+// withPrefix keeps the candidates starting with partial and prepends line.
+func withPrefix(candidates []string, partial, line string) []string {
+	var out []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, partial) {
+			out = append(out, line+c)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Split breaks a line into arguments on whitespace. Double quotes group
+// words into one argument.
+func Split(line string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inQuotes, hasArg := false, false
+	for _, ch := range line {
+		switch {
+		case ch == '"':
+			inQuotes = !inQuotes
+			hasArg = true
+		case (ch == ' ' || ch == '\t') && !inQuotes:
+			if hasArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				hasArg = false
+			}
+		default:
+			cur.WriteRune(ch)
+			hasArg = true
+		}
+	}
+	if inQuotes {
+		return nil, errors.New("unterminated quote")
+	}
+	if hasArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// Int parses an integer argument.
+func Int(arg, name string) (int, error) {
+	v, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer, got %q", name, arg)
+	}
+	return v, nil
+}
+
+// Float parses a number argument.
+func Float(arg, name string) (float64, error) {
+	v, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number, got %q", name, arg)
+	}
+	return v, nil
+}
+
+// Coord parses a coordinate. "~" is origin and "~n" is relative to it.
+func Coord(arg string, origin float64) (float64, error) {
+	if rest, ok := strings.CutPrefix(arg, "~"); ok {
+		if rest == "" {
+			return origin, nil
+		}
+		v, err := Float(rest, "relative coordinate")
+		return origin + v, err
+	}
+	return Float(arg, "coordinate")
+}
@@ -0,0 +1,172 @@
+package console
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// newTestRegistry registers "echo", which joins its one or two arguments,
+// and "color", which completes its argument from a fixed list.
+func newTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+	r := NewRegistry()
+	cmds := []Command{
+		{Name: "echo", Usage: "<a> [b]", MinArgs: 1, MaxArgs: 2, Run: func(args []string) (string, error) {
+			return strings.Join(args, "|"), nil
+		}},
+		{Name: "color", Usage: "<name>", MinArgs: 1, MaxArgs: -1, Run: func(args []string) (string, error) {
+			return args[0], nil
+		}, Complete: func(args []string) []string {
+			return []string{"red", "green", "grey"}
+		}},
+	}
+	for _, c := range cmds {
+		if err := r.Register(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return r
+}
+
+func TestRegister(t *testing.T) {
+	r := newTestRegistry(t)
+	run := func(args []string) (string, error) { return "", nil }
+	for _, c := range []Command{
+		{Name: "", Run: run},
+		{Name: "two words", Run: run},
+		{Name: "echo", Run: run},
+		{Name: "norun"},
+	} {
+		if err := r.Register(c); err == nil {
+			t.Errorf("Register(%q) succeeded", c.Name)
+		}
+	}
+}
+
+func TestExecute(t *testing.T) {
+	r := newTestRegistry(t)
+	tests := []struct {
+		line    string
+		want    string
+		wantErr string
+	}{
+		{"echo a", "a", ""},
+		{"echo a b", "a|b", ""},
+		{"  echo   a\tb  ", "a|b", ""},
+		{`echo "a b" c`, "a b|c", ""},
+		{`echo ""`, "", ""},
+		{"echo", "", "usage: echo <a> [b]"},
+		{"echo a b c", "", "usage: echo <a> [b]"},
+		{"color a b c d", "a", ""},
+		{`echo "a`, "", "unterminated quote"},
+		{"", "", ""},
+		{"nope", "", "unknown command: nope"},
+	}
+	for _, tt := range tests {
+		got, err := r.Execute(tt.line)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Execute(%q) error = %v, want %q", tt.line, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Execute(%q) = %q, %v; want %q", tt.line, got, err, tt.want)
+		}
+	}
+	if _, err := r.Execute("nope"); !errors.Is(err, ErrUnknownCommand) {
+		t.Errorf("unknown command error %v does not wrap ErrUnknownCommand", err)
+	}
+	if _, err := r.Execute("help nope"); !errors.Is(err, ErrUnknownCommand) {
+		t.Errorf("help for an unknown command returned %v", err)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a  b\tc", []string{"a", "b", "c"}},
+		{`say "hello world"`, []string{"say", "hello world"}},
+		{`a"b c"d`, []string{"ab cd"}},
+		{`x "" y`, []string{"x", "", "y"}},
+	}
+	for _, tt := range tests {
+		got, err := Split(tt.line)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("Split(%q) = %q, %v; want %q", tt.line, got, err, tt.want)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	r := newTestRegistry(t)
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", []string{"color", "echo", "help"}},
+		{"e", []string{"echo"}},
+		{"color gr", []string{"color green", "color grey"}},
+		{"color ", []string{"color green", "color grey", "color red"}},
+		{"help c", []string{"help color"}},
+		{"echo a", nil}, // No completer
+		{"nope a", nil},
+		{`color "gr`, nil},
+	}
+	for _, tt := range tests {
+		if got := r.Complete(tt.line); !slices.Equal(got, tt.want) {
+			t.Errorf("Complete(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+
+	c := New(r)
+	c.Input = "col"
+	c.Complete()
+	if c.Input != "color " {
+		t.Errorf("single match completed to %q, want %q", c.Input, "color ")
+	}
+	c.Input = "color g"
+	c.Complete()
+	if c.Input != "color gre" {
+		t.Errorf("several matches completed to %q, want the common prefix %q", c.Input, "color gre")
+	}
+}
+
+func TestHistory(t *testing.T) {
+	c := New(newTestRegistry(t))
+	for _, line := range []string{"echo 1", "echo 2", "echo 2", "  ", "nope"} {
+		c.Input = line
+		c.Submit()
+	}
+	if last := c.Output[len(c.Output)-1]; last != "error: unknown command: nope" {
+		t.Errorf("last output line %q, want the error", last)
+	}
+
+	// Repeats and blank lines are not recorded
+	for _, want := range []string{"nope", "echo 2", "echo 1", "echo 1"} {
+		c.HistoryPrev()
+		if c.Input != want {
+			t.Fatalf("HistoryPrev gave %q, want %q", c.Input, want)
+		}
+	}
+	for _, want := range []string{"echo 2", "nope", "", ""} {
+		c.HistoryNext()
+		if c.Input != want {
+			t.Fatalf("HistoryNext gave %q, want %q", c.Input, want)
+		}
+	}
+
+	for i := 0; i < maxHistory+10; i++ {
+		c.Input = "echo " + strings.Repeat("x", i+1)
+		c.Submit()
+	}
+	if len(c.history) != maxHistory {
+		t.Errorf("history holds %d lines, want %d", len(c.history), maxHistory)
+	}
+}
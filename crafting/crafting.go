@@ -0,0 +1,382 @@
+package crafting
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"something/inventory"
+	"something/item"
+)
+
+// RecipeType distinguishes shaped from shapeless recipes.
+type RecipeType string
+
+const (
+	Shaped    RecipeType = "shaped"
+	Shapeless RecipeType = "shapeless"
+)
+
+// Recipe turns a set of input items into an output stack.
+type Recipe struct {
+	Name        string
+	Type        RecipeType
+	Width       int           // Pattern width (shaped only)
+	Height      int           // Pattern height (shaped only)
+	Pattern     []item.ItemID // Row-major pattern, ItemNone for gaps (shaped only)
+	Ingredients []item.ItemID // Unordered inputs (shapeless only)
+	Output      item.ItemStack
+}
+
+// Inputs returns the number of each item the recipe consumes.
+func (r *Recipe) Inputs() map[item.ItemID]int {
+	inputs := make(map[item.ItemID]int)
+	for _, id := range append(append([]item.ItemID{}, r.Pattern...), r.Ingredients...) {
+		if id != item.ItemNone {
+			inputs[id]++
+		}
+	}
+	return inputs
+}
+
+// Grid is a 2x2 or 3x3 crafting grid.
+type Grid struct {
+	Size  int
+	Slots []item.ItemStack // Row-major, Size*Size entries
+}
+
+func NewGrid(size int) *Grid {
+	return &Grid{Size: size, Slots: make([]item.ItemStack, size*size)}
+}
+
+// Set places a stack in the grid cell at column x, row y.
+func (g *Grid) Set(x, y int, stack item.ItemStack) {
+	g.Slots[y*g.Size+x] = stack
+}
+
+// trimmed returns the grid's item IDs cropped to the bounding box of
+// non-empty cells.
+func (g *Grid) trimmed() (ids []item.ItemID, w, h int) {
+	minX, minY, maxX, maxY := g.Size, g.Size, -1, -1
+	for y := 0; y < g.Size; y++ {
+		for x := 0; x < g.Size; x++ {
+			if g.Slots[y*g.Size+x].IsEmpty() {
+				continue
+			}
+			minX, maxX = min(minX, x), max(maxX, x)
+			minY, maxY = min(minY, y), max(maxY, y)
+		}
+	}
+	if maxX < 0 {
+		return nil, 0, 0
+	}
+	w, h = maxX-minX+1, maxY-minY+1
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			s := g.Slots[y*g.Size+x]
+			if s.IsEmpty() {
+				ids = append(ids, item.ItemNone)
+			} else {
+				ids = append(ids, s.ID)
+			}
+		}
+	}
+	return ids, w, h
+}
+
+// Matches reports whether the grid contents satisfy the recipe. Shaped
+// recipes may sit anywhere in the grid and also match when mirrored.
+func (r *Recipe) Matches(g *Grid) bool {
+	ids, w, h := g.trimmed()
+	if len(ids) == 0 {
+		return false
+	}
+	if r.Type == Shapeless {
+		return sameItems(ids, r.Ingredients)
+	}
+	if w != r.Width || h != r.Height {
+		return false
+	}
+	return slices.Equal(ids, r.Pattern) || slices.Equal(ids, mirror(r.Pattern, r.Width, r.Height))
+}
+
+// Registry holds all loaded recipes.
+type Registry struct {
+	Recipes []*Recipe
+}
+
+// Match returns the first recipe satisfied by the grid.
+func (reg *Registry) Match(g *Grid) (*Recipe, bool) {
+	for _, r := range reg.Recipes {
+		if r.Matches(g) {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// Craft consumes one item from every occupied grid cell and returns the
+// output of the matching recipe.
+func (reg *Registry) Craft(g *Grid) (item.ItemStack, bool) {
+	r, ok := reg.Match(g)
+	if !ok {
+		return item.ItemStack{}, false
+	}
+	for i := range g.Slots {
+		if g.Slots[i].IsEmpty() {
+			continue
+		}
+		g.Slots[i].Count--
+		if g.Slots[i].Count == 0 {
+			g.Slots[i] = item.ItemStack{}
+		}
+	}
+	return r.Output, true
+}
+
+// CanCraft reports whether the inventory holds every input of the recipe.
+func CanCraft(inv *inventory.Inventory, r *Recipe) bool {
+	for id, n := range r.Inputs() {
+		if inv.Count(id) < n {
+			return false
+		}
+	}
+	return true
+}
+
+// ErrNoRoom is returned when the crafted output would not fit.
+var ErrNoRoom = errors.New("not enough room for the crafted items")
+
+// CraftFromInventory removes the recipe's inputs from the inventory and adds
+// its output, returning the stack received. Nothing changes unless the whole
+// output fits once the inputs are gone.
+func CraftFromInventory(inv *inventory.Inventory, r *Recipe) (item.ItemStack, error) {
+	if !CanCraft(inv, r) {
+		return item.ItemStack{}, fmt.Errorf("missing ingredients for recipe %q", r.Name)
+	}
+	trial := inv.Clone()
+	for id, n := range r.Inputs() {
+		trial.Remove(id, n)
+	}
+	if trial.Add(r.Output) > 0 {
+		return item.ItemStack{}, fmt.Errorf("failed to craft %q: %w", r.Name, ErrNoRoom)
+	}
+	copy(inv.Slots, trial.Slots)
+	return r.Output, nil
+}
+
+// Craftable returns the recipes the inventory currently has inputs for.
+func (reg *Registry) Craftable(inv *inventory.Inventory) []*Recipe {
+	var out []*Recipe
+	for _, r := range reg.Recipes {
+		if CanCraft(inv, r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// recipeFile is the on-disk JSON representation of a recipe.
+type recipeFile struct {
+	Name        string            `json:"name"`
+	Type        RecipeType        `json:"type"`
+	Pattern     []string          `json:"pattern"`     // Rows of key characters, space for gaps
+	Key         map[string]string `json:"key"`         // Character -> item name
+	Ingredients []string          `json:"ingredients"` // Item names
+	Result      struct {
+		Item  string `json:"item"`
+		Count int    `json:"count"`
+	} `json:"result"`
+}
+
+// LoadRecipes reads every *.json file in dir. Each file holds an array of
+// recipes. Unknown items, malformed patterns and conflicting recipes are
+// all reported in the returned error.
+func LoadRecipes(dir string) (*Registry, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	reg := &Registry{}
+	var errs []error
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recipes: %w", err)
+		}
+		var files []recipeFile
+		if err := json.Unmarshal(data, &files); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		for _, f := range files {
+			r, err := f.build()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", path, err))
+				continue
+			}
+			reg.Recipes = append(reg.Recipes, r)
+		}
+	}
+	errs = append(errs, reg.conflicts()...)
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return reg, nil
+}
+
+func (f recipeFile) build() (*Recipe, error) {
+	r := &Recipe{Name: f.Name, Type: f.Type}
+	if r.Name == "" {
+		return nil, errors.New("recipe without a name")
+	}
+	out, ok := item.ByName(f.Result.Item)
+	if !ok {
+		return nil, fmt.Errorf("recipe %q: unknown result item %q", f.Name, f.Result.Item)
+	}
+	count := f.Result.Count
+	if count <= 0 {
+		count = 1
+	}
+	r.Output = item.NewStack(out, count)
+
+	switch f.Type {
+	case Shaped:
+		r.Height = len(f.Pattern)
+		if r.Height == 0 || r.Height > 3 {
+			return nil, fmt.Errorf("recipe %q: pattern must have 1-3 rows", f.Name)
+		}
+		r.Width = len(f.Pattern[0])
+		for _, row := range f.Pattern {
+			if len(row) != r.Width || r.Width == 0 || r.Width > 3 {
+				return nil, fmt.Errorf("recipe %q: pattern rows must be 1-3 equal-length columns", f.Name)
+			}
+			for _, c := range row {
+				if c == ' ' {
+					r.Pattern = append(r.Pattern, item.ItemNone)
+					continue
+				}
+				name, ok := f.Key[string(c)]
+				if !ok {
+					return nil, fmt.Errorf("recipe %q: pattern key %q is not defined", f.Name, c)
+				}
+				id, ok := item.ByName(name)
+				if !ok {
+					return nil, fmt.Errorf("recipe %q: unknown item %q", f.Name, name)
+				}
+				r.Pattern = append(r.Pattern, id)
+			}
+		}
+	case Shapeless:
+		if len(f.Ingredients) == 0 || len(f.Ingredients) > 9 {
+			return nil, fmt.Errorf("recipe %q: shapeless recipes need 1-9 ingredients", f.Name)
+		}
+		for _, name := range f.Ingredients {
+			id, ok := item.ByName(name)
+			if !ok {
+				return nil, fmt.Errorf("recipe %q: unknown item %q", f.Name, name)
+			}
+			r.Ingredients = append(r.Ingredients, id)
+		}
+	default:
+		return nil, fmt.Errorf("recipe %q: unknown type %q", f.Name, f.Type)
+	}
+	return r, nil
+}
+
+// conflicts reports duplicate names and recipes that accept the same input.
+// A shaped recipe also conflicts with a shapeless one whose ingredients are
+// the same items, since its pattern satisfies both.
+func (reg *Registry) conflicts() []error {
+	var errs []error
+	names := make(map[string]bool)
+	seen := make(map[string]string)
+	shapedItems := make(map[string]string) // Ingredient multiset -> shaped recipe name
+	for _, r := range reg.Recipes {
+		if names[r.Name] {
+			errs = append(errs, fmt.Errorf("duplicate recipe name %q", r.Name))
+		}
+		names[r.Name] = true
+		keys := []string{r.signature(r.Pattern)}
+		if r.Type == Shaped {
+			keys = append(keys, r.signature(mirror(r.Pattern, r.Width, r.Height)))
+		}
+		var others []string
+		for _, key := range keys {
+			others = append(others, seen[key])
+		}
+		items := itemsKey(append(append([]item.ItemID{}, r.Pattern...), r.Ingredients...))
+		if r.Type == Shaped {
+			others = append(others, seen[items]) // A shapeless recipe with the same items
+		} else {
+			others = append(others, shapedItems[items])
+		}
+		for _, other := range others {
+			if other != "" && other != r.Name {
+				errs = append(errs, fmt.Errorf("recipe %q conflicts with %q", r.Name, other))
+				break
+			}
+		}
+		for _, key := range keys {
+			seen[key] = r.Name
+		}
+		if r.Type == Shaped {
+			shapedItems[items] = r.Name
+		}
+	}
+	return errs
+}
+
+// signature builds a comparable key for the recipe's input layout.
+func (r *Recipe) signature(pattern []item.ItemID) string {
+	if r.Type == Shapeless {
+		return itemsKey(r.Ingredients)
+	}
+	return fmt.Sprintf("shaped:%dx%d:%v", r.Width, r.Height, pattern)
+}
+
+// itemsKey builds a comparable key for an unordered list of items, ignoring
+// empty cells.
+func itemsKey(ids []item.ItemID) string {
+	var sorted []item.ItemID
+	for _, id := range ids {
+		if id != item.ItemNone {
+			sorted = append(sorted, id)
+		}
+	}
+	slices.Sort(sorted)
+	return fmt.Sprintf("shapeless:%v", sorted)
+}
+
+func mirror(pattern []item.ItemID, w, h int) []item.ItemID {
+	out := make([]item.ItemID, len(pattern))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			out[y*w+x] = pattern[y*w+(w-1-x)]
+		}
+	}
+	return out
+}
+
+// sameItems compares the non-empty grid cells with a shapeless ingredient list.
+func sameItems(grid, ingredients []item.ItemID) bool {
+	counts := make(map[item.ItemID]int)
+	for _, id := range grid {
+		if id != item.ItemNone {
+			counts[id]++
+		}
+	}
+	for _, id := range ingredients {
+		counts[id]--
+	}
+	for _, n := range counts {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
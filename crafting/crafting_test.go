@@ -0,0 +1,201 @@
+package crafting
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"something/inventory"
+	"something/item"
+)
+
+// load writes each recipe file into a temporary directory and loads it.
+func load(t *testing.T, files ...string) (*Registry, error) {
+	t.Helper()
+	dir := t.TempDir()
+	for i, data := range files {
+		path := filepath.Join(dir, string(rune('a'+i))+".json")
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return LoadRecipes(dir)
+}
+
+// grid builds a grid from rows of cells: S stone, D dirt, G grass, P
+// pebble, space or . empty.
+func grid(rows ...string) *Grid {
+	cells := map[rune]item.ItemID{'S': item.ItemStone, 'D': item.ItemDirt, 'G': item.ItemGrass, 'P': item.ItemPebble}
+	g := NewGrid(len(rows))
+	for y, row := range rows {
+		for x, c := range row {
+			if id, ok := cells[c]; ok {
+				g.Set(x, y, item.NewStack(id, 1))
+			}
+		}
+	}
+	return g
+}
+
+const testRecipes = `[
+	{"name": "hook", "type": "shaped", "pattern": ["SP", " P"], "key": {"S": "stone", "P": "pebble"}, "result": {"item": "stone_pickaxe"}},
+	{"name": "mix", "type": "shapeless", "ingredients": ["grass", "dirt", "dirt"], "result": {"item": "stone", "count": 2}}
+]`
+
+func TestMatches(t *testing.T) {
+	reg, err := load(t, testRecipes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		grid *Grid
+		want string // Matching recipe, "" for none
+	}{
+		{"exact", grid("SP", " P"), "hook"},
+		{"offset in a larger grid", grid("...", ".SP", "..P"), "hook"},
+		{"top right of a larger grid", grid(".SP", "..P", "..."), "hook"},
+		{"mirrored", grid("PS", "P "), "hook"},
+		{"upside down", grid(" P", "SP"), ""},
+		{"extra item", grid("SP.", ".P.", "..S"), ""},
+		{"missing item", grid("SP", "  "), ""},
+		{"gap breaks the shape", grid("S.P", "..P", "..."), ""},
+		{"empty", grid("...", "...", "..."), ""},
+		{"shapeless in a row", grid("GDD", "...", "..."), "mix"},
+		{"shapeless scattered", grid("D..", "..G", ".D."), "mix"},
+		{"shapeless short", grid("GD", ".."), ""},
+		{"shapeless with extra", grid("GDD", "D..", "..."), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, ok := reg.Match(tt.grid)
+			got := ""
+			if ok {
+				got = r.Name
+			}
+			if got != tt.want {
+				t.Errorf("matched %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCraftConsumesGrid(t *testing.T) {
+	reg, err := load(t, testRecipes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := grid("SP", " P")
+	g.Slots[0].Count = 2
+	out, ok := reg.Craft(g)
+	if !ok || out.ID != item.ItemStonePickaxe || out.Count != 1 {
+		t.Fatalf("Craft = %+v, %v; want one stone pickaxe", out, ok)
+	}
+	if g.Slots[0].Count != 1 || !g.Slots[1].IsEmpty() || !g.Slots[3].IsEmpty() {
+		t.Errorf("grid after crafting: %+v", g.Slots)
+	}
+	if _, ok := reg.Craft(g); ok {
+		t.Error("crafted from a grid that no longer matches")
+	}
+}
+
+func TestLoadRecipesErrors(t *testing.T) {
+	shaped := `{"name": "a", "type": "shaped", "pattern": ["SD"], "key": {"S": "stone", "D": "dirt"}, "result": {"item": "grass"}}`
+	tests := []struct {
+		name    string
+		files   []string
+		wantErr string
+	}{
+		{"unknown result", []string{`[{"name": "a", "type": "shapeless", "ingredients": ["stone"], "result": {"item": "gold"}}]`}, `unknown result item "gold"`},
+		{"unknown ingredient", []string{`[{"name": "a", "type": "shapeless", "ingredients": ["gold"], "result": {"item": "stone"}}]`}, `unknown item "gold"`},
+		{"unknown key item", []string{`[{"name": "a", "type": "shaped", "pattern": ["G"], "key": {"G": "gold"}, "result": {"item": "stone"}}]`}, `unknown item "gold"`},
+		{"undefined key", []string{`[{"name": "a", "type": "shaped", "pattern": ["X"], "key": {}, "result": {"item": "stone"}}]`}, `pattern key 'X' is not defined`},
+		{"ragged pattern", []string{`[{"name": "a", "type": "shaped", "pattern": ["SS", "S"], "key": {"S": "stone"}, "result": {"item": "dirt"}}]`}, "equal-length"},
+		{"too many rows", []string{`[{"name": "a", "type": "shaped", "pattern": ["S", "S", "S", "S"], "key": {"S": "stone"}, "result": {"item": "dirt"}}]`}, "1-3 rows"},
+		{"unknown type", []string{`[{"name": "a", "type": "smelted", "result": {"item": "dirt"}}]`}, `unknown type "smelted"`},
+		{"no name", []string{`[{"type": "shapeless", "ingredients": ["stone"], "result": {"item": "dirt"}}]`}, "without a name"},
+		{"bad JSON", []string{`[{`}, "failed to parse"},
+		{"duplicate name", []string{`[` + shaped + `]`, `[{"name": "a", "type": "shapeless", "ingredients": ["grass"], "result": {"item": "dirt"}}]`}, `duplicate recipe name "a"`},
+		{"same shape", []string{`[` + shaped + `]`, `[` + strings.Replace(shaped, `"a"`, `"b"`, 1) + `]`}, `recipe "b" conflicts with "a"`},
+		{"mirrored shape", []string{`[` + shaped + `]`, `[{"name": "b", "type": "shaped", "pattern": ["DS"], "key": {"S": "stone", "D": "dirt"}, "result": {"item": "pebble"}}]`}, `recipe "b" conflicts with "a"`},
+		{"shapeless in another order", []string{`[{"name": "a", "type": "shapeless", "ingredients": ["stone", "dirt"], "result": {"item": "grass"}}, {"name": "b", "type": "shapeless", "ingredients": ["dirt", "stone"], "result": {"item": "pebble"}}]`}, `recipe "b" conflicts with "a"`},
+		{"shaped then shapeless", []string{`[` + shaped + `]`, `[{"name": "b", "type": "shapeless", "ingredients": ["dirt", "stone"], "result": {"item": "pebble"}}]`}, `recipe "b" conflicts with "a"`},
+		{"shapeless then shaped", []string{`[{"name": "b", "type": "shapeless", "ingredients": ["dirt", "stone"], "result": {"item": "pebble"}}]`, `[` + shaped + `]`}, `recipe "a" conflicts with "b"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg, err := load(t, tt.files...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("LoadRecipes error = %v, want one containing %q", err, tt.wantErr)
+			}
+			if reg != nil {
+				t.Error("LoadRecipes returned a registry along with the error")
+			}
+		})
+	}
+
+	// Different items in the same shape do not conflict
+	if _, err := load(t, `[`+shaped+`]`, `[{"name": "b", "type": "shaped", "pattern": ["SS"], "key": {"S": "stone"}, "result": {"item": "dirt"}}]`); err != nil {
+		t.Errorf("distinct recipes: %v", err)
+	}
+	if _, err := LoadRecipes("../assets/recipes"); err != nil {
+		t.Errorf("bundled recipes: %v", err)
+	}
+}
+
+func TestCraftFromInventory(t *testing.T) {
+	reg, err := load(t, `[{"name": "pebbles", "type": "shapeless", "ingredients": ["stone"], "result": {"item": "pebble", "count": 4}}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pebbles := reg.Recipes[0]
+	// full fills every slot but the first with dirt.
+	full := func(first item.ItemStack) *inventory.Inventory {
+		inv := inventory.NewInventory()
+		for i := range inv.Slots {
+			inv.Slots[i] = item.NewStack(item.ItemDirt, item.DefaultMaxStack)
+		}
+		inv.Slots[0] = first
+		return inv
+	}
+	tests := []struct {
+		name    string
+		inv     *inventory.Inventory
+		wantErr string
+		pebbles int // Pebbles afterwards
+		stone   int // Stone afterwards
+	}{
+		{"no room", full(item.NewStack(item.ItemStone, 3)), ErrNoRoom.Error(), 0, 3},
+		{"empty inventory", inventory.NewInventory(), "missing ingredients", 0, 0},
+		{"uses the freed slot", full(item.NewStack(item.ItemStone, 1)), "", 4, 0},
+		{"tops up existing pebbles", func() *inventory.Inventory {
+			inv := inventory.NewInventory()
+			inv.Slots[0] = item.NewStack(item.ItemStone, 2)
+			inv.Slots[1] = item.NewStack(item.ItemPebble, 62)
+			return inv
+		}(), "", 66, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := CraftFromInventory(tt.inv, pebbles)
+			if tt.wantErr == "" {
+				if err != nil || out.ID != item.ItemPebble || out.Count != 4 {
+					t.Fatalf("CraftFromInventory = %+v, %v; want 4 pebbles", out, err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+			if got := tt.inv.Count(item.ItemPebble); got != tt.pebbles {
+				t.Errorf("%d pebbles afterwards, want %d", got, tt.pebbles)
+			}
+			if got := tt.inv.Count(item.ItemStone); got != tt.stone {
+				t.Errorf("%d stone afterwards, want %d", got, tt.stone)
+			}
+		})
+	}
+	if _, err := CraftFromInventory(full(item.NewStack(item.ItemStone, 3)), pebbles); !errors.Is(err, ErrNoRoom) {
+		t.Errorf("error %v does not wrap ErrNoRoom", err)
+	}
+}
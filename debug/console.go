@@ -0,0 +1,37 @@
+package debug
+
+import (
+	"strings"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	consoleLines  = 10  // Scrollback lines shown above the input
+	consoleBlink  = 0.5 // Seconds per cursor blink phase
+	consolePrompt = "> "
+)
+
+var consoleBackground = mgl32.Vec4{0, 0, 0, 0.6}
+
+// renderConsole draws the developer console across the bottom of the
+// window: recent output above an input line with a blinking cursor.
+func (d *Debug) renderConsole(width, height int) {
+	lineHeight := d.Text.Atlas.LineHeight
+	output := d.Console.Output
+	if len(output) > consoleLines {
+		output = output[len(output)-consoleLines:]
+	}
+	panel := lineHeight*float32(len(output)+1) + margin
+	d.shapes.rect(0, 0, float32(width), panel, consoleBackground)
+	d.shapes.flush(width, height)
+
+	input := consolePrompt + d.Console.Input
+	if int(glfw.GetTime()/consoleBlink)%2 == 0 {
+		input += "_"
+	}
+	lines := append(append([]string{}, output...), input)
+	top := panel - margin/2 - d.Text.Atlas.Ascent
+	d.Text.Draw(strings.Join(lines, "\n"), margin, top, style)
+}
@@ -0,0 +1,95 @@
+package debug
+
+import (
+	"fmt"
+	"strings"
+
+	"something/metrics"
+	"something/profile"
+	"something/text"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	graphBarWidth = 1    // Pixels per frame sample
+	graphPxPerMs  = 2    // Vertical pixels per millisecond of frame time
+	graphMaxMs    = 50   // Taller frames are clipped
+	frameBudget   = 16.7 // Milliseconds per frame at 60 FPS
+	barHeight     = 14   // Height of the profiler's stacked bar
+	barPxPerMs    = 10   // Stacked bar pixels per millisecond
+	legendSwatch  = 8    // Size of the legend colour squares
+)
+
+var (
+	graphBackground = mgl32.Vec4{0, 0, 0, 0.4}
+	graphGood       = mgl32.Vec4{0.3, 0.9, 0.3, 0.9}
+	graphSlow       = mgl32.Vec4{0.95, 0.8, 0.2, 0.9}
+	graphBad        = mgl32.Vec4{0.95, 0.3, 0.25, 0.9}
+	graphBudget     = mgl32.Vec4{1, 1, 1, 0.5}
+)
+
+// sectionColors cycles through distinguishable colours for profiler scopes.
+var sectionColors = []mgl32.Vec4{
+	{0.35, 0.6, 1, 1},
+	{1, 0.55, 0.2, 1},
+	{0.4, 0.85, 0.4, 1},
+	{0.9, 0.35, 0.6, 1},
+	{0.95, 0.85, 0.3, 1},
+	{0.55, 0.4, 0.9, 1},
+	{0.3, 0.85, 0.85, 1},
+	{0.7, 0.7, 0.7, 1},
+}
+
+// renderGraphs draws the frame-time history and the profiler's stacked bar
+// in the bottom-right corner.
+func (d *Debug) renderGraphs(width, height int) {
+	samples := metrics.Default.Samples(metrics.FrameTime)
+	right := float32(width) - margin
+	graphW := float32(len(samples) * graphBarWidth)
+	graphH := float32(graphMaxMs * graphPxPerMs)
+	left := right - graphW
+	bottom := float32(margin)
+	d.shapes.rect(left, bottom, right, bottom+graphH, graphBackground)
+	for i, ms := range samples {
+		c := graphGood
+		if ms > 2*frameBudget {
+			c = graphBad
+		} else if ms > frameBudget {
+			c = graphSlow
+		}
+		x := left + float32(i*graphBarWidth)
+		d.shapes.rect(x, bottom, x+graphBarWidth, bottom+float32(min(ms, graphMaxMs))*graphPxPerMs, c)
+	}
+	for _, budget := range []float32{frameBudget, 2 * frameBudget} {
+		y := bottom + budget*graphPxPerMs
+		d.shapes.rect(left, y, right, y+1, graphBudget)
+	}
+
+	// Stacked bar of each scope's self time, right-aligned above the graph
+	sections := profile.Default.Sections()
+	barBottom := bottom + graphH + margin
+	x := right
+	var legend []string
+	for i := len(sections) - 1; i >= 0; i-- {
+		w := float32(sections[i].Self.Seconds()*1000) * barPxPerMs
+		d.shapes.rect(x-w, barBottom, x, barBottom+barHeight, sectionColors[i%len(sectionColors)])
+		x -= w
+	}
+	lineHeight := d.Text.LineHeight(1)
+	top := barBottom + barHeight + margin + float32(len(sections))*lineHeight
+	for i, sec := range sections {
+		legend = append(legend, fmt.Sprintf("%s %.2f ms", sec.Name, sec.Self.Seconds()*1000))
+		y := top - float32(i+1)*lineHeight + (lineHeight-legendSwatch)/2
+		d.shapes.rect(right-legendSwatch, y, right, y+legendSwatch, sectionColors[i%len(sectionColors)])
+	}
+	d.shapes.flush(width, height)
+
+	label := style
+	label.Align = text.AlignRight
+	baseline := top - d.Text.Atlas.Ascent
+	d.Text.Draw(strings.Join(legend, "\n"), right-legendSwatch-margin, baseline, label)
+	if profile.Default.Tracing() {
+		d.Text.Draw("Recording trace (F3 to save)", right, top+margin, label)
+	}
+}
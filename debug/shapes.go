@@ -0,0 +1,75 @@
+package debug
+
+import (
+	"something/metrics"
+	"something/shader"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// shapes batches solid-colour rectangles in window pixel coordinates and
+// draws them in one call.
+type shapes struct {
+	program  *shader.Program
+	vao, vbo uint32
+	vertices []float32 // x, y, r, g, b, a per vertex
+}
+
+func newShapes() (*shapes, error) {
+	program, err := shader.Load("shapes")
+	if err != nil {
+		return nil, err
+	}
+	s := &shapes{program: program}
+	gl.GenVertexArrays(1, &s.vao)
+	gl.GenBuffers(1, &s.vbo)
+	gl.BindVertexArray(s.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, s.vbo)
+	gl.VertexAttribPointer(0, 2, gl.FLOAT, false, 6*4, gl.PtrOffset(0))
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(1, 4, gl.FLOAT, false, 6*4, gl.PtrOffset(2*4))
+	gl.EnableVertexAttribArray(1)
+	gl.BindVertexArray(0)
+	return s, nil
+}
+
+// rect queues a rectangle between two corners.
+func (s *shapes) rect(x0, y0, x1, y1 float32, c mgl32.Vec4) {
+	s.vertices = append(s.vertices,
+		x0, y0, c[0], c[1], c[2], c[3],
+		x1, y0, c[0], c[1], c[2], c[3],
+		x1, y1, c[0], c[1], c[2], c[3],
+		x0, y0, c[0], c[1], c[2], c[3],
+		x1, y1, c[0], c[1], c[2], c[3],
+		x0, y1, c[0], c[1], c[2], c[3],
+	)
+}
+
+// flush draws and clears the queued rectangles.
+func (s *shapes) flush(width, height int) {
+	if len(s.vertices) == 0 {
+		return
+	}
+	ortho := mgl32.Ortho(0, float32(width), 0, float32(height), -1, 1)
+	s.program.Use()
+	s.program.SetMat4("projection", ortho)
+	depthTest := gl.IsEnabled(gl.DEPTH_TEST)
+	gl.Disable(gl.DEPTH_TEST)
+	gl.BindVertexArray(s.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, s.vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, len(s.vertices)*4, gl.Ptr(s.vertices), gl.STREAM_DRAW)
+	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(s.vertices)/6))
+	metrics.Add(metrics.DrawCalls, 1)
+	gl.BindVertexArray(0)
+	if depthTest {
+		gl.Enable(gl.DEPTH_TEST)
+	}
+	s.vertices = s.vertices[:0]
+}
+
+func (s *shapes) cleanup() {
+	s.program.Delete()
+	gl.DeleteVertexArrays(1, &s.vao)
+	gl.DeleteBuffers(1, &s.vbo)
+}
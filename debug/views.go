@@ -0,0 +1,56 @@
+package debug
+
+import (
+	"strconv"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// view is a render mode the debug menu toggles with a function key.
+type view struct {
+	key     glfw.Key
+	name    string
+	enabled *bool
+}
+
+// AddView registers a render mode toggled by key while the menu is open.
+// The flag is owned by the caller, so other code may flip it too.
+func (d *Debug) AddView(key glfw.Key, name string, enabled *bool) {
+	d.views = append(d.views, view{key: key, name: name, enabled: enabled})
+}
+
+// HandleKey toggles the view bound to key and reports whether one was.
+// Views only respond while the menu is enabled.
+func (d *Debug) HandleKey(key glfw.Key) bool {
+	if !d.Enabled {
+		return false
+	}
+	for _, v := range d.views {
+		if v.key == key {
+			*v.enabled = !*v.enabled
+			return true
+		}
+	}
+	return false
+}
+
+// viewLines lists the views with their keys and state.
+func (d *Debug) viewLines() []string {
+	var out []string
+	for _, v := range d.views {
+		state := "off"
+		if *v.enabled {
+			state = "on"
+		}
+		out = append(out, keyName(v.key)+" "+v.name+": "+state)
+	}
+	return out
+}
+
+// keyName labels function keys; views are expected to use them.
+func keyName(key glfw.Key) string {
+	if key >= glfw.KeyF1 && key <= glfw.KeyF12 {
+		return "F" + strconv.Itoa(int(key-glfw.KeyF1)+1)
+	}
+	return "?"
+}
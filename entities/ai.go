@@ -0,0 +1,228 @@
+package entities
+
+import (
+	"math"
+	"math/rand"
+
+	"something/pathfind"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Behaviour is the state of a MobAI.
+type Behaviour int
+
+const (
+	BehaviourIdle Behaviour = iota
+	BehaviourWander
+	BehaviourFollow
+	BehaviourFlee
+	BehaviourGraze
+)
+
+func (b Behaviour) String() string {
+	switch b {
+	case BehaviourWander:
+		return "wander"
+	case BehaviourFollow:
+		return "follow"
+	case BehaviourFlee:
+		return "flee"
+	case BehaviourGraze:
+		return "graze"
+	}
+	return "idle"
+}
+
+const (
+	followRange   = 8.0  // Player distance at which food is noticed
+	followStop    = 2.5  // Distance at which a follower stops walking
+	wanderRange   = 8    // Max blocks a wander goal is chosen from the entity
+	fleeDistance  = 10.0 // How far a startled entity runs
+	fleeDuration  = 5.0  // Seconds spent fleeing after being startled
+	repathDelay   = 1.0  // Seconds between path updates when following
+	waypointReach = 0.3  // Horizontal distance at which a waypoint counts as reached
+	jumpVelocity  = 8.0  // Same jump impulse as the player
+)
+
+// Startler is implemented by AIs that react to being hit or scared.
+type Startler interface {
+	Startle(from mgl32.Vec3)
+}
+
+// grazer is implemented by renderables with a grazing pose.
+type grazer interface {
+	SetGrazing(bool)
+}
+
+// MobAI is a behaviour state machine that moves along A* paths.
+type MobAI struct {
+	State      Behaviour
+	Agent      pathfind.Agent
+	WalkSpeed  float32
+	RunSpeed   float32
+	FollowItem string // Item name that makes the mob follow the player
+
+	timer  float32
+	repath float32
+	path   []pathfind.Pos
+	threat mgl32.Vec3
+	rng    *rand.Rand
+}
+
+// NewMobAI seeds the behaviour from the entity ID so each entity acts
+// differently but reproducibly.
+func NewMobAI(id EntityID, agent pathfind.Agent, walkSpeed, runSpeed float32, followItem string) *MobAI {
+	return &MobAI{
+		Agent:      agent,
+		WalkSpeed:  walkSpeed,
+		RunSpeed:   runSpeed,
+		FollowItem: followItem,
+		rng:        rand.New(rand.NewSource(int64(id))),
+	}
+}
+
+// Startle makes the mob flee away from a point.
+func (a *MobAI) Startle(from mgl32.Vec3) {
+	a.threat = from
+	a.setState(BehaviourFlee, fleeDuration)
+}
+
+func (a *MobAI) setState(s Behaviour, duration float32) {
+	a.State = s
+	a.timer = duration
+	a.path = nil
+	a.repath = 0
+}
+
+func (a *MobAI) Update(m *Manager, id EntityID, dt float32) {
+	t, v, c := m.Transforms[id], m.Velocities[id], m.Colliders[id]
+	if v == nil || c == nil {
+		return
+	}
+	a.timer -= dt
+	a.repath -= dt
+
+	toPlayer := m.Player.Position.Sub(t.Position)
+	wantsFood := a.FollowItem != "" && m.Player.Holding == a.FollowItem && toPlayer.Len() < followRange
+	switch {
+	case a.State == BehaviourFlee && a.timer > 0:
+	case wantsFood && a.State != BehaviourFollow:
+		a.setState(BehaviourFollow, 0)
+	case a.State == BehaviourFollow && !wantsFood:
+		a.setState(BehaviourIdle, 1)
+	case a.State != BehaviourFollow && a.timer <= 0:
+		a.chooseIdleBehaviour()
+	}
+	if g, ok := m.Renderables[id].(grazer); ok {
+		g.SetGrazing(a.State == BehaviourGraze)
+	}
+
+	speed := a.WalkSpeed
+	switch a.State {
+	case BehaviourWander:
+		if a.path == nil && !a.planWander(m.Terrain, t.Position) {
+			a.setState(BehaviourIdle, 1)
+		}
+	case BehaviourFollow:
+		if toPlayer.Len() < followStop {
+			a.path = nil
+		} else if a.repath <= 0 {
+			a.repath = repathDelay
+			a.planTo(m.Terrain, t.Position, m.Player.Position)
+		}
+	case BehaviourFlee:
+		speed = a.RunSpeed
+		if a.path == nil {
+			away := t.Position.Sub(a.threat)
+			away[1] = 0
+			if away.Len() == 0 {
+				away = headingVector(a.rng.Float32() * 360)
+			}
+			if !a.planTo(m.Terrain, t.Position, t.Position.Add(away.Normalize().Mul(fleeDistance))) {
+				a.path = []pathfind.Pos{} // No route; stand still until the timer runs out
+			}
+		}
+	}
+	a.steer(t, v, c, speed)
+}
+
+// chooseIdleBehaviour picks the next calm behaviour at random.
+func (a *MobAI) chooseIdleBehaviour() {
+	switch r := a.rng.Float32(); {
+	case r < 0.3:
+		a.setState(BehaviourIdle, 2+a.rng.Float32()*3)
+	case r < 0.75:
+		a.setState(BehaviourWander, 10)
+	default:
+		a.setState(BehaviourGraze, 3+a.rng.Float32()*4)
+	}
+}
+
+// planWander picks a random reachable goal near pos.
+func (a *MobAI) planWander(terrain Terrain, pos mgl32.Vec3) bool {
+	for attempt := 0; attempt < 5; attempt++ {
+		dx := a.rng.Intn(2*wanderRange+1) - wanderRange
+		dz := a.rng.Intn(2*wanderRange+1) - wanderRange
+		goal := pos.Add(mgl32.Vec3{float32(dx), 0, float32(dz)})
+		if a.planTo(terrain, pos, goal) {
+			return true
+		}
+	}
+	return false
+}
+
+// planTo finds a path from pos to the ground nearest goal.
+func (a *MobAI) planTo(terrain Terrain, pos, goal mgl32.Vec3) bool {
+	if terrain == nil {
+		return false
+	}
+	start := blockPos(pos)
+	end, ok := pathfind.FindGround(terrain, a.Agent, int(math.Floor(float64(goal.X()))), int(math.Floor(float64(goal.Y()))), int(math.Floor(float64(goal.Z()))), 4)
+	if !ok {
+		return false
+	}
+	path, ok := pathfind.FindPath(terrain, a.Agent, start, end, 0)
+	if !ok {
+		return false
+	}
+	a.path = path
+	return true
+}
+
+// steer walks toward the next waypoint, jumping up ledges.
+func (a *MobAI) steer(t *Transform, v *Velocity, c *Collider, speed float32) {
+	for len(a.path) > 0 {
+		next := a.path[0]
+		target := mgl32.Vec3{float32(next[0]) + 0.5, t.Position.Y(), float32(next[2]) + 0.5}
+		delta := target.Sub(t.Position)
+		if delta.Len() < waypointReach {
+			a.path = a.path[1:]
+			continue
+		}
+		dir := delta.Normalize()
+		v.Linear[0], v.Linear[2] = dir.X()*speed, dir.Z()*speed
+		if float32(next[1]) > t.Position.Y()+0.5 && c.OnGround {
+			v.Linear[1] = jumpVelocity
+		}
+		return
+	}
+	if len(a.path) == 0 && a.path != nil && a.State == BehaviourWander {
+		a.setState(BehaviourIdle, 1+a.rng.Float32()*2) // Arrived
+	}
+	v.Linear[0], v.Linear[2] = 0, 0
+}
+
+func blockPos(p mgl32.Vec3) pathfind.Pos {
+	return pathfind.Pos{
+		int(math.Floor(float64(p.X()))),
+		int(math.Floor(float64(p.Y()) + 0.01)), // Resting feet sit a hair above the block
+		int(math.Floor(float64(p.Z()))),
+	}
+}
+
+// headingVector converts a yaw in degrees to a horizontal unit vector.
+func headingVector(yaw float32) mgl32.Vec3 {
+	rad := float64(mgl32.DegToRad(yaw))
+	return mgl32.Vec3{float32(math.Cos(rad)), 0, float32(math.Sin(rad))}
+}
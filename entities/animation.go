@@ -0,0 +1,147 @@
+package entities
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// AnimCycle is the movement animation currently playing.
+type AnimCycle int
+
+const (
+	AnimIdle AnimCycle = iota
+	AnimWalk
+	AnimRun
+)
+
+const (
+	walkThreshold = 0.1 // Horizontal speed above which the walk cycle plays
+	runThreshold  = 3.0 // Horizontal speed above which the run cycle plays
+	strideLength  = 1.6 // Blocks travelled per full leg cycle
+	turnRate      = 270 // Degrees per second when turning to face movement
+)
+
+// AnimState holds the playback state used to sample model animations.
+type AnimState struct {
+	Time      float32   // Animation timer in seconds
+	WalkCycle float32   // 0 to 1 for walk cycle
+	Cycle     AnimCycle // Cycle chosen from the current speed
+	Stride    float32   // 0 = standing, 1 = full stride; eases between cycles
+	HeadYaw   float32   // Head-look rotation in radians
+	Grazing   bool      // Lowers the head to the ground
+	graze     float32   // Eased 0..1 grazing blend
+}
+
+// Animated renderables advance their animation once per tick.
+type Animated interface {
+	Animate(dt float32, t *Transform, v *Velocity)
+}
+
+// AnimationSystem advances animations and turns moving entities to face
+// their direction of travel.
+func AnimationSystem(m *Manager, dt float32) {
+	for id, r := range m.Renderables {
+		t := m.Transforms[id]
+		v, hasVelocity := m.Velocities[id]
+		if !hasVelocity {
+			v = &Velocity{}
+		}
+		if speed := horizontalSpeed(v.Linear); speed > walkThreshold {
+			target := mgl32.RadToDeg(float32(math.Atan2(float64(v.Linear.Z()), float64(v.Linear.X()))))
+			t.Yaw = turnTowards(t.Yaw, target, turnRate*dt)
+		}
+		if a, ok := r.(Animated); ok {
+			a.Animate(dt, t, v)
+		}
+	}
+}
+
+// Animate advances the mob's animation from its velocity.
+func (p *Mob) Animate(dt float32, t *Transform, v *Velocity) {
+	p.AnimState.Advance(dt, horizontalSpeed(v.Linear))
+}
+
+// SetGrazing switches the grazing pose on or off.
+func (p *Mob) SetGrazing(grazing bool) {
+	p.AnimState.Grazing = grazing
+}
+
+// Advance moves the animation forward by dt at the given ground speed.
+func (a *AnimState) Advance(dt, speed float32) {
+	a.Time += dt
+	grazeTarget := float32(0)
+	if a.Grazing {
+		grazeTarget = 1
+	}
+	a.graze += (grazeTarget - a.graze) * min(1, dt*3)
+	switch {
+	case speed > runThreshold:
+		a.Cycle = AnimRun
+	case speed > walkThreshold:
+		a.Cycle = AnimWalk
+	default:
+		a.Cycle = AnimIdle
+	}
+	target := float32(0)
+	if a.Cycle != AnimIdle {
+		target = 1
+		a.WalkCycle = float32(math.Mod(float64(a.WalkCycle+speed*dt/strideLength), 1))
+	}
+	// Ease the stride so legs settle instead of snapping when stopping
+	a.Stride += (target - a.Stride) * min(1, dt*6)
+	if a.Cycle == AnimIdle {
+		// Slow head-look sweep while standing
+		a.HeadYaw = 0.6 * float32(math.Sin(float64(a.Time)*0.7))
+	} else {
+		a.HeadYaw *= 1 - min(1, dt*4)
+	}
+}
+
+// Pose returns a rotation (radians around X, Y, Z) for each part of the
+// model. The movement cycle ("walk" or "run") is sampled by stride phase and
+// blended over "idle"; "graze" is layered on top, and the procedural
+// head-look is added to the "head" part.
+func (a *AnimState) Pose(def *ModelDef) []mgl32.Vec3 {
+	cycle := "walk"
+	if a.Cycle == AnimRun {
+		cycle = "run"
+	}
+	idle := def.Sample("idle", a.Time)
+	move := def.Sample(cycle, a.WalkCycle*def.Animations[cycle].Length)
+	graze := def.Sample("graze", a.Time)
+	pose := make([]mgl32.Vec3, len(def.Parts))
+	for i, part := range def.Parts {
+		pose[i] = idle[i].Mul(1 - a.Stride).Add(move[i].Mul(a.Stride)).Add(graze[i].Mul(a.graze))
+		if part.Name == "head" {
+			pose[i][1] += a.HeadYaw * (1 - a.graze)
+		}
+	}
+	return pose
+}
+
+// rotateAround builds a rotation (X, then Y, then Z) about a pivot point.
+func rotateAround(pivot, rot mgl32.Vec3) mgl32.Mat4 {
+	if rot == (mgl32.Vec3{}) {
+		return mgl32.Ident4()
+	}
+	r := mgl32.HomogRotate3DZ(rot.Z()).Mul4(mgl32.HomogRotate3DY(rot.Y())).Mul4(mgl32.HomogRotate3DX(rot.X()))
+	return mgl32.Translate3D(pivot.X(), pivot.Y(), pivot.Z()).
+		Mul4(r).
+		Mul4(mgl32.Translate3D(-pivot.X(), -pivot.Y(), -pivot.Z()))
+}
+
+func horizontalSpeed(v mgl32.Vec3) float32 {
+	return float32(math.Hypot(float64(v.X()), float64(v.Z())))
+}
+
+// turnTowards rotates yaw toward target by at most step degrees.
+func turnTowards(yaw, target, step float32) float32 {
+	diff := float32(math.Mod(float64(target-yaw)+540, 360)) - 180
+	if diff > step {
+		diff = step
+	} else if diff < -step {
+		diff = -step
+	}
+	return float32(math.Mod(float64(yaw+diff)+360, 360))
+}
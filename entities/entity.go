@@ -0,0 +1,559 @@
+package entities
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"something/lines"
+	"something/metrics"
+	"something/physics"
+	"something/profile"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// ChunkSize matches world.ChunkSize; entities are bucketed by chunk column.
+const ChunkSize = 16
+
+// EntityID identifies an entity. IDs are never reused within a save.
+type EntityID uint64
+
+// Transform places an entity in the world. Position is the bottom-center
+// of the entity, like Player.Position.
+type Transform struct {
+	Position mgl32.Vec3
+	Yaw      float32 // Facing angle around +Y in degrees, 0 = +X
+}
+
+// Velocity is an entity's movement in blocks per second.
+type Velocity struct {
+	Linear mgl32.Vec3
+}
+
+// Collider is an axis-aligned box around Transform.Position.
+type Collider struct {
+	Width    float32
+	Height   float32
+	OnGround bool
+}
+
+// Renderable draws an entity at its transform.
+type Renderable interface {
+	Render(view, projection mgl32.Mat4, t Transform)
+}
+
+// DepthRenderable is a Renderable that can also cast shadows.
+type DepthRenderable interface {
+	RenderDepth(setModel func(model mgl32.Mat4), t Transform)
+}
+
+// AI decides an entity's movement each tick.
+type AI interface {
+	Update(m *Manager, id EntityID, dt float32)
+}
+
+// Terrain answers voxel solidity queries; *world.World implements it.
+type Terrain = physics.Terrain
+
+// System runs once per tick over the manager's entities.
+type System func(m *Manager, dt float32)
+
+// SpawnFunc attaches a type's components to a freshly created entity.
+type SpawnFunc func(m *Manager, id EntityID, t Transform) error
+
+// PlayerState is what AIs can observe about the player.
+type PlayerState struct {
+	Position mgl32.Vec3
+	Holding  string // Item name in the selected hotbar slot
+}
+
+// Manager owns all live entities and their components.
+type Manager struct {
+	Terrain     Terrain
+	SaveDir     string        // Per-chunk entity files; empty disables saving
+	Player      PlayerState   // Updated by the game each tick
+	PlayerBody  *physics.Body // Pushed by colliding entities when set
+	Types       map[EntityID]string
+	Transforms  map[EntityID]*Transform
+	Velocities  map[EntityID]*Velocity
+	Colliders   map[EntityID]*Collider
+	Renderables map[EntityID]Renderable
+	AIs         map[EntityID]AI
+
+	nextID  EntityID
+	systems []System
+	spawns  map[string]SpawnFunc
+	chunkOf map[EntityID][2]int
+	loaded  map[[2]int]bool
+}
+
+// NewManager creates a manager with the default AI, movement and animation systems.
+func NewManager(terrain Terrain) *Manager {
+	m := &Manager{
+		Terrain:     terrain,
+		Types:       make(map[EntityID]string),
+		Transforms:  make(map[EntityID]*Transform),
+		Velocities:  make(map[EntityID]*Velocity),
+		Colliders:   make(map[EntityID]*Collider),
+		Renderables: make(map[EntityID]Renderable),
+		AIs:         make(map[EntityID]AI),
+		nextID:      1,
+		spawns:      make(map[string]SpawnFunc),
+		chunkOf:     make(map[EntityID][2]int),
+		loaded:      make(map[[2]int]bool),
+	}
+	m.AddSystem(AISystem)
+	m.AddSystem(MovementSystem)
+	m.AddSystem(AnimationSystem)
+	return m
+}
+
+// AddSystem appends a system to the tick order.
+func (m *Manager) AddSystem(s System) {
+	m.systems = append(m.systems, s)
+}
+
+// RegisterType makes an entity type spawnable by name and loadable from saves.
+func (m *Manager) RegisterType(name string, spawn SpawnFunc) {
+	m.spawns[name] = spawn
+}
+
+// TypeNames returns the registered entity types, sorted.
+func (m *Manager) TypeNames() []string {
+	names := make([]string, 0, len(m.spawns))
+	for name := range m.spawns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Spawn creates a new entity of a registered type.
+func (m *Manager) Spawn(typeName string, t Transform) (EntityID, error) {
+	id := m.nextID
+	if err := m.spawnWithID(id, typeName, t); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (m *Manager) spawnWithID(id EntityID, typeName string, t Transform) error {
+	spawn, ok := m.spawns[typeName]
+	if !ok {
+		return fmt.Errorf("unknown entity type %q", typeName)
+	}
+	if _, exists := m.Types[id]; exists {
+		return fmt.Errorf("entity %d already exists", id)
+	}
+	m.Types[id] = typeName
+	m.Transforms[id] = &t
+	if err := spawn(m, id, t); err != nil {
+		m.Remove(id)
+		return fmt.Errorf("failed to spawn %s: %w", typeName, err)
+	}
+	m.chunkOf[id] = chunkKey(t.Position)
+	if id >= m.nextID {
+		m.nextID = id + 1
+	}
+	return nil
+}
+
+// Remove deletes an entity and all of its components.
+func (m *Manager) Remove(id EntityID) {
+	delete(m.Types, id)
+	delete(m.Transforms, id)
+	delete(m.Velocities, id)
+	delete(m.Colliders, id)
+	delete(m.Renderables, id)
+	delete(m.AIs, id)
+	delete(m.chunkOf, id)
+}
+
+// Startle tells an entity's AI that it was hit or scared from a point.
+func (m *Manager) Startle(id EntityID, from mgl32.Vec3) {
+	if s, ok := m.AIs[id].(Startler); ok {
+		s.Startle(from)
+	}
+}
+
+// IDs returns every live entity ID in ascending order.
+func (m *Manager) IDs() []EntityID {
+	ids := make([]EntityID, 0, len(m.Types))
+	for id := range m.Types {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// CountType returns the number of live entities of a type.
+func (m *Manager) CountType(typeName string) int {
+	n := 0
+	for _, t := range m.Types {
+		if t == typeName {
+			n++
+		}
+	}
+	return n
+}
+
+// Update runs every system, then moves entities that crossed into an
+// unloaded chunk out to that chunk's save.
+func (m *Manager) Update(dt float32) error {
+	defer profile.Begin("entities.update")()
+	for _, s := range m.systems {
+		s(m, dt)
+	}
+	left := make(map[[2]int]bool)
+	arrived := make(map[[2]int]bool)
+	for _, id := range m.IDs() {
+		key := chunkKey(m.Transforms[id].Position)
+		from := m.chunkOf[id]
+		m.chunkOf[id] = key
+		if m.loaded[key] {
+			if key != from {
+				arrived[key] = true
+			}
+			continue
+		}
+		if err := m.appendSaved(key, m.saved(id)); err != nil {
+			return err
+		}
+		m.Remove(id)
+		left[from] = true
+	}
+	// Drop the moved entities from their old chunk's file too. Chunks that
+	// gained entities are written first, so a crash in between leaves a
+	// duplicate, which LoadChunk skips, rather than losing them.
+	if len(left) > 0 {
+		for _, keys := range []map[[2]int]bool{arrived, left} {
+			for key := range keys {
+				if m.loaded[key] {
+					if err := m.saveChunk(key); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	metrics.Set(metrics.Entities, float64(len(m.Types)))
+	return nil
+}
+
+// Render draws every renderable entity.
+func (m *Manager) Render(view, projection mgl32.Mat4) {
+	defer profile.Begin("entities.render")()
+	for id, r := range m.Renderables {
+		r.Render(view, projection, *m.Transforms[id])
+	}
+}
+
+// RenderDepth draws every shadow-casting entity for a depth-only pass.
+func (m *Manager) RenderDepth(setModel func(model mgl32.Mat4)) {
+	for id, r := range m.Renderables {
+		if d, ok := r.(DepthRenderable); ok {
+			d.RenderDepth(setModel, *m.Transforms[id])
+		}
+	}
+}
+
+// RayHit returns the nearest entity whose collider the ray passes through.
+func (m *Manager) RayHit(origin, dir mgl32.Vec3, maxDist float32) (EntityID, float32, bool) {
+	var hitID EntityID
+	best, found := maxDist, false
+	for id := range m.Colliders {
+		boxMin, boxMax := m.bounds(id)
+		if t, ok := rayBox(origin, dir, boxMin, boxMax, best); ok {
+			hitID, best, found = id, t, true
+		}
+	}
+	return hitID, best, found
+}
+
+// DrawColliders submits every collider's box to the debug line batch.
+func (m *Manager) DrawColliders(c mgl32.Vec4) {
+	for id := range m.Colliders {
+		boxMin, boxMax := m.bounds(id)
+		lines.Box(boxMin, boxMax, c)
+	}
+}
+
+// bounds returns the corners of an entity's collider.
+func (m *Manager) bounds(id EntityID) (boxMin, boxMax mgl32.Vec3) {
+	c, p := m.Colliders[id], m.Transforms[id].Position
+	boxMin = mgl32.Vec3{p.X() - c.Width/2, p.Y(), p.Z() - c.Width/2}
+	boxMax = mgl32.Vec3{p.X() + c.Width/2, p.Y() + c.Height, p.Z() + c.Width/2}
+	return boxMin, boxMax
+}
+
+func rayBox(origin, dir, boxMin, boxMax mgl32.Vec3, maxDist float32) (float32, bool) {
+	tNear, tFar := float32(0), maxDist
+	for i := 0; i < 3; i++ {
+		if dir[i] == 0 {
+			if origin[i] < boxMin[i] || origin[i] > boxMax[i] {
+				return 0, false
+			}
+			continue
+		}
+		t1 := (boxMin[i] - origin[i]) / dir[i]
+		t2 := (boxMax[i] - origin[i]) / dir[i]
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		tNear, tFar = max(tNear, t1), min(tFar, t2)
+		if tNear > tFar {
+			return 0, false
+		}
+	}
+	return tNear, true
+}
+
+// AISystem lets every AI component steer its entity.
+func AISystem(m *Manager, dt float32) {
+	for _, id := range m.IDs() {
+		if ai, ok := m.AIs[id]; ok {
+			ai.Update(m, id, dt)
+		}
+	}
+}
+
+// MovementSystem steps every entity with a collider as a physics body,
+// then pushes overlapping entities and the player apart.
+func MovementSystem(m *Manager, dt float32) {
+	var bodies []*physics.Body
+	var owners []EntityID
+	for _, id := range m.IDs() {
+		v, ok := m.Velocities[id]
+		if !ok {
+			continue
+		}
+		t := m.Transforms[id]
+		c, hasCollider := m.Colliders[id]
+		if !hasCollider || m.Terrain == nil {
+			t.Position = t.Position.Add(v.Linear.Mul(dt))
+			continue
+		}
+		b := &physics.Body{Position: t.Position, Velocity: v.Linear, Width: c.Width, Height: c.Height}
+		b.Step(m.Terrain, dt)
+		bodies = append(bodies, b)
+		owners = append(owners, id)
+	}
+	for i := range bodies {
+		for j := i + 1; j < len(bodies); j++ {
+			physics.Push(m.Terrain, bodies[i], bodies[j], 1)
+		}
+		if m.PlayerBody != nil {
+			physics.Push(m.Terrain, bodies[i], m.PlayerBody, 1)
+		}
+	}
+	for i, id := range owners {
+		m.Transforms[id].Position = bodies[i].Position
+		m.Velocities[id].Linear = bodies[i].Velocity
+		m.Colliders[id].OnGround = bodies[i].OnGround
+	}
+}
+
+func chunkKey(pos mgl32.Vec3) [2]int {
+	return [2]int{
+		int(math.Floor(float64(pos.X()) / ChunkSize)),
+		int(math.Floor(float64(pos.Z()) / ChunkSize)),
+	}
+}
+
+// savedEntity is the on-disk form of an entity.
+type savedEntity struct {
+	ID       EntityID   `json:"id"`
+	Type     string     `json:"type"`
+	Position mgl32.Vec3 `json:"position"`
+	Yaw      float32    `json:"yaw"`
+	Velocity mgl32.Vec3 `json:"velocity"`
+}
+
+func (m *Manager) saved(id EntityID) savedEntity {
+	t := m.Transforms[id]
+	s := savedEntity{ID: id, Type: m.Types[id], Position: t.Position, Yaw: t.Yaw}
+	if v, ok := m.Velocities[id]; ok {
+		s.Velocity = v.Linear
+	}
+	return s
+}
+
+// LoadChunk marks a chunk as loaded and respawns the entities saved in it.
+// Either every saved entity is spawned or none are. The chunk's file is kept
+// until the chunk is next saved, so a crash cannot lose its entities.
+// Chunk files are written one at a time, so a crash while an entity moves
+// between chunks can leave it in two files; copies of entities that are
+// already live are logged and skipped.
+func (m *Manager) LoadChunk(x, z int) error {
+	key := [2]int{x, z}
+	saved, err := m.readSaved(key)
+	if err != nil {
+		return err
+	}
+	spawned := make([]EntityID, 0, len(saved))
+	for _, s := range saved {
+		if _, live := m.Types[s.ID]; live {
+			log.Printf("skipping duplicate entity %d in chunk %d,%d", s.ID, x, z)
+			continue
+		}
+		if err := m.spawnWithID(s.ID, s.Type, Transform{Position: s.Position, Yaw: s.Yaw}); err != nil {
+			for _, id := range spawned {
+				m.Remove(id)
+			}
+			return fmt.Errorf("failed to load chunk %d,%d: %w", x, z, err)
+		}
+		spawned = append(spawned, s.ID)
+		if v, ok := m.Velocities[s.ID]; ok {
+			v.Linear = s.Velocity
+		}
+	}
+	m.loaded[key] = true
+	return nil
+}
+
+// UnloadChunk saves the chunk's entities and removes them from the manager.
+func (m *Manager) UnloadChunk(x, z int) error {
+	key := [2]int{x, z}
+	if err := m.saveChunk(key); err != nil {
+		return err
+	}
+	delete(m.loaded, key)
+	for _, id := range m.IDs() {
+		if m.chunkOf[id] == key {
+			m.Remove(id)
+		}
+	}
+	return nil
+}
+
+// Save writes every loaded chunk's entities and the ID counter, keeping
+// them loaded.
+func (m *Manager) Save() error {
+	for key := range m.loaded {
+		if err := m.saveChunk(key); err != nil {
+			return err
+		}
+	}
+	return m.writeMeta()
+}
+
+// Close saves every loaded chunk and the ID counter.
+func (m *Manager) Close() error {
+	for key := range m.loaded {
+		if err := m.UnloadChunk(key[0], key[1]); err != nil {
+			return err
+		}
+	}
+	return m.writeMeta()
+}
+
+// saveChunk replaces a loaded chunk's file with the entities now in it.
+func (m *Manager) saveChunk(key [2]int) error {
+	var saved []savedEntity
+	for _, id := range m.IDs() {
+		if m.chunkOf[id] == key {
+			saved = append(saved, m.saved(id))
+		}
+	}
+	return m.writeSaved(key, saved)
+}
+
+// Open reads the ID counter from SaveDir so new IDs never collide with
+// entities saved in unloaded chunks.
+func (m *Manager) Open() error {
+	if m.SaveDir == "" {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(m.SaveDir, "meta.json"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read entity meta: %w", err)
+	}
+	var meta struct {
+		NextID EntityID `json:"next_id"`
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return fmt.Errorf("failed to parse entity meta: %w", err)
+	}
+	m.nextID = max(m.nextID, meta.NextID)
+	return nil
+}
+
+func (m *Manager) writeMeta() error {
+	if m.SaveDir == "" {
+		return nil
+	}
+	data, err := json.Marshal(map[string]EntityID{"next_id": m.nextID})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.SaveDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create entity directory: %w", err)
+	}
+	return os.WriteFile(filepath.Join(m.SaveDir, "meta.json"), data, 0o644)
+}
+
+func (m *Manager) chunkPath(key [2]int) string {
+	return filepath.Join(m.SaveDir, fmt.Sprintf("%d_%d.json", key[0], key[1]))
+}
+
+func (m *Manager) readSaved(key [2]int) ([]savedEntity, error) {
+	if m.SaveDir == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(m.chunkPath(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chunk entities: %w", err)
+	}
+	var saved []savedEntity
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", m.chunkPath(key), err)
+	}
+	return saved, nil
+}
+
+func (m *Manager) appendSaved(key [2]int, entities ...savedEntity) error {
+	if len(entities) == 0 {
+		return nil
+	}
+	existing, err := m.readSaved(key)
+	if err != nil {
+		return err
+	}
+	// Replace any stale copy of an entity rather than saving it twice
+	existing = slices.DeleteFunc(existing, func(s savedEntity) bool {
+		return slices.ContainsFunc(entities, func(e savedEntity) bool { return e.ID == s.ID })
+	})
+	return m.writeSaved(key, append(existing, entities...))
+}
+
+func (m *Manager) writeSaved(key [2]int, saved []savedEntity) error {
+	if m.SaveDir == "" {
+		return nil
+	}
+	if len(saved) == 0 {
+		if err := os.Remove(m.chunkPath(key)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(m.SaveDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create entity directory: %w", err)
+	}
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(m.chunkPath(key), data, 0o644)
+}
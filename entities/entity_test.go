@@ -0,0 +1,204 @@
+package entities
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// newTestManager returns a manager without terrain, saving to a temporary
+// directory, with "crate" (no components) and "cart" (a velocity) types.
+func newTestManager(t *testing.T, dir string) *Manager {
+	t.Helper()
+	m := NewManager(nil)
+	m.SaveDir = dir
+	m.RegisterType("crate", func(m *Manager, id EntityID, t Transform) error { return nil })
+	m.RegisterType("cart", func(m *Manager, id EntityID, t Transform) error {
+		m.Velocities[id] = &Velocity{}
+		return nil
+	})
+	if err := m.Open(); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func spawn(t *testing.T, m *Manager, typeName string, pos mgl32.Vec3) EntityID {
+	t.Helper()
+	id, err := m.Spawn(typeName, Transform{Position: pos})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// savedIDs returns the IDs in a chunk's file.
+func savedIDs(t *testing.T, m *Manager, x, z int) []EntityID {
+	t.Helper()
+	saved, err := m.readSaved([2]int{x, z})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []EntityID
+	for _, s := range saved {
+		ids = append(ids, s.ID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestSaveUnloadReload(t *testing.T) {
+	m := newTestManager(t, t.TempDir())
+	if err := m.LoadChunk(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	crate := spawn(t, m, "crate", mgl32.Vec3{2, 5, 3})
+	cart := spawn(t, m, "cart", mgl32.Vec3{8, 5, 8})
+	m.Velocities[cart].Linear = mgl32.Vec3{0, 0, 1}
+	m.Transforms[crate].Yaw = 45
+
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if got := savedIDs(t, m, 0, 0); !slices.Equal(got, []EntityID{crate, cart}) {
+		t.Errorf("chunk file holds %v after Save, want %v", got, []EntityID{crate, cart})
+	}
+	if len(m.IDs()) != 2 {
+		t.Error("Save unloaded entities")
+	}
+
+	if err := m.UnloadChunk(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.IDs()) != 0 {
+		t.Errorf("%d entities live after unloading their chunk", len(m.IDs()))
+	}
+	if err := m.LoadChunk(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(m.IDs(), []EntityID{crate, cart}) {
+		t.Fatalf("reloaded %v, want %v", m.IDs(), []EntityID{crate, cart})
+	}
+	if got := *m.Transforms[crate]; got != (Transform{Position: mgl32.Vec3{2, 5, 3}, Yaw: 45}) {
+		t.Errorf("crate reloaded at %+v", got)
+	}
+	if got := m.Velocities[cart].Linear; got != (mgl32.Vec3{0, 0, 1}) {
+		t.Errorf("cart reloaded with velocity %v", got)
+	}
+
+	// IDs are not reused by a later session
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	next := newTestManager(t, m.SaveDir)
+	if id := spawn(t, next, "crate", mgl32.Vec3{}); id <= cart {
+		t.Errorf("new session spawned ID %d, want more than %d", id, cart)
+	}
+}
+
+func TestMoveAcrossChunks(t *testing.T) {
+	m := newTestManager(t, t.TempDir())
+	for _, x := range []int{0, 1} {
+		if err := m.LoadChunk(x, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stays := spawn(t, m, "crate", mgl32.Vec3{1, 5, 1})
+	toLoaded := spawn(t, m, "cart", mgl32.Vec3{15.5, 5, 1})
+	toUnloaded := spawn(t, m, "cart", mgl32.Vec3{1, 5, 1})
+	m.Velocities[toLoaded].Linear = mgl32.Vec3{1, 0, 0}
+	m.Velocities[toUnloaded].Linear = mgl32.Vec3{0, 0, -2}
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Update(1); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(m.IDs(), []EntityID{stays, toLoaded}) {
+		t.Fatalf("live entities %v, want %v", m.IDs(), []EntityID{stays, toLoaded})
+	}
+	// Leaving for an unloaded chunk moves the entity between files at once
+	if got := savedIDs(t, m, 0, -1); !slices.Equal(got, []EntityID{toUnloaded}) {
+		t.Errorf("unloaded chunk file holds %v, want %v", got, []EntityID{toUnloaded})
+	}
+	// Rewriting the source chunk also writes the loaded chunk that gained an
+	// entity, so the entity is never missing from both files
+	if got := savedIDs(t, m, 0, 0); !slices.Equal(got, []EntityID{stays}) {
+		t.Errorf("chunk 0,0 file holds %v, want %v", got, []EntityID{stays})
+	}
+	if got := savedIDs(t, m, 1, 0); !slices.Equal(got, []EntityID{toLoaded}) {
+		t.Errorf("chunk 1,0 file holds %v, want %v", got, []EntityID{toLoaded})
+	}
+
+	if err := m.LoadChunk(0, -1); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Transforms[toUnloaded]; got == nil || got.Position != (mgl32.Vec3{1, 5, -1}) {
+		t.Errorf("entity that left reloaded at %v, want 1 5 -1", got)
+	}
+}
+
+func TestLoadSkipsDuplicates(t *testing.T) {
+	m := newTestManager(t, t.TempDir())
+	// A crash mid-move left entity 1 in both files
+	files := map[[2]int][]savedEntity{
+		{0, 0}: {{ID: 1, Type: "crate", Position: mgl32.Vec3{15, 5, 1}}, {ID: 2, Type: "crate"}},
+		{1, 0}: {{ID: 1, Type: "crate", Position: mgl32.Vec3{16, 5, 1}}, {ID: 3, Type: "crate", Position: mgl32.Vec3{20, 5, 1}}},
+	}
+	for key, saved := range files {
+		if err := m.writeSaved(key, saved); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, x := range []int{0, 1} {
+		if err := m.LoadChunk(x, 0); err != nil {
+			t.Fatalf("LoadChunk(%d, 0): %v", x, err)
+		}
+	}
+	if !slices.Equal(m.IDs(), []EntityID{1, 2, 3}) {
+		t.Fatalf("live entities %v, want 1 2 3", m.IDs())
+	}
+	if got := m.Transforms[1].Position; got != (mgl32.Vec3{15, 5, 1}) {
+		t.Errorf("duplicate replaced the live copy, now at %v", got)
+	}
+	// The stale copy is dropped on the next save
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if got := savedIDs(t, m, 1, 0); !slices.Equal(got, []EntityID{3}) {
+		t.Errorf("chunk 1,0 file holds %v after saving, want 3", got)
+	}
+
+	// Appending an entity already in a file replaces it
+	if err := m.appendSaved([2]int{5, 5}, savedEntity{ID: 9, Type: "crate"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.appendSaved([2]int{5, 5}, savedEntity{ID: 9, Type: "crate", Yaw: 90}); err != nil {
+		t.Fatal(err)
+	}
+	if saved, _ := m.readSaved([2]int{5, 5}); len(saved) != 1 || saved[0].Yaw != 90 {
+		t.Errorf("chunk file holds %+v, want one updated entity", saved)
+	}
+}
+
+func TestLoadFailureSpawnsNothing(t *testing.T) {
+	dir := t.TempDir()
+	m := newTestManager(t, dir)
+	bad := []savedEntity{{ID: 1, Type: "crate"}, {ID: 2, Type: "dragon"}}
+	if err := m.writeSaved([2]int{0, 0}, bad); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.LoadChunk(0, 0); err == nil {
+		t.Fatal("loaded a chunk with an unknown entity type")
+	}
+	if len(m.IDs()) != 0 || m.loaded[[2]int{0, 0}] {
+		t.Errorf("failed load left entities %v, loaded %v", m.IDs(), m.loaded[[2]int{0, 0}])
+	}
+	if _, err := os.Stat(filepath.Join(dir, "0_0.json")); err != nil {
+		t.Errorf("failed load removed the chunk file: %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package entities
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"something/pathfind"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// PonyType is the entity type name used for ponies.
+const PonyType = "pony"
+
+// MobDef is an entity type file: a model plus the body and behaviour
+// settings of a MobAI.
+type MobDef struct {
+	Name       string  `json:"name"`
+	Model      string  `json:"model"`       // Model file path
+	Width      float32 `json:"width"`       // Bounding box width
+	Height     float32 `json:"height"`      // Bounding box height
+	WalkSpeed  float32 `json:"walk_speed"`  // Blocks per second
+	RunSpeed   float32 `json:"run_speed"`   // Blocks per second when fleeing
+	FollowItem string  `json:"follow_item"` // Held item the mob follows, if any
+	StepHeight int     `json:"step_height"`
+	MaxDrop    int     `json:"max_drop"`
+
+	Spawn *SpawnRule `json:"spawn"` // Natural spawning; nil never spawns naturally
+}
+
+// LoadMobDefs reads every *.json entity type file in dir, sorted by name.
+func LoadMobDefs(dir string) ([]MobDef, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entity types: %w", err)
+	}
+	var defs []MobDef
+	seen := make(map[string]bool)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read entity type: %w", err)
+		}
+		var def MobDef
+		if err := json.Unmarshal(data, &def); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		if def.Name == "" || seen[def.Name] {
+			return nil, fmt.Errorf("%s: missing or duplicate name %q", file, def.Name)
+		}
+		if def.Model == "" || def.Width <= 0 || def.Height <= 0 {
+			return nil, fmt.Errorf("%s: model, width and height are required", file)
+		}
+		seen[def.Name] = true
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs, nil
+}
+
+// Agent returns the body used when pathfinding for the mob.
+func (d MobDef) Agent() pathfind.Agent {
+	return pathfind.Agent{Width: d.Width, Height: d.Height, StepHeight: d.StepHeight, MaxDrop: d.MaxDrop}
+}
+
+// ModelCache shares loaded models between entity types by path.
+type ModelCache struct {
+	models map[string]*Model
+}
+
+// NewModelCache creates an empty cache.
+func NewModelCache() *ModelCache {
+	return &ModelCache{models: make(map[string]*Model)}
+}
+
+// Load returns the model at path, loading it on first use.
+func (c *ModelCache) Load(path string) (*Model, error) {
+	if m, ok := c.models[path]; ok {
+		return m, nil
+	}
+	def, err := LoadModelDef(path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := NewModel(def)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create model %s: %w", path, err)
+	}
+	c.models[path] = m
+	return m, nil
+}
+
+// Cleanup releases every cached model.
+func (c *ModelCache) Cleanup() {
+	for _, m := range c.models {
+		m.Cleanup()
+	}
+	c.models = make(map[string]*Model)
+}
+
+// RegisterMobTypes loads the entity type files in dir and makes each type
+// spawnable on the manager. It returns the loaded definitions.
+func RegisterMobTypes(m *Manager, dir string, models *ModelCache) ([]MobDef, error) {
+	defs, err := LoadMobDefs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, def := range defs {
+		model, err := models.Load(def.Model)
+		if err != nil {
+			return nil, err
+		}
+		RegisterMob(m, def, model)
+	}
+	return defs, nil
+}
+
+// RegisterMob makes a mob type spawnable using a shared model.
+func RegisterMob(m *Manager, def MobDef, model *Model) {
+	m.RegisterType(def.Name, func(m *Manager, id EntityID, t Transform) error {
+		m.Velocities[id] = &Velocity{}
+		m.Colliders[id] = &Collider{Width: def.Width, Height: def.Height}
+		m.Renderables[id] = &Mob{Model: model}
+		m.AIs[id] = NewMobAI(id, def.Agent(), def.WalkSpeed, def.RunSpeed, def.FollowItem)
+		return nil
+	})
+}
+
+// Mob is the renderable component of a model-driven entity.
+type Mob struct {
+	Model     *Model
+	AnimState AnimState
+}
+
+// Render draws the mob at the entity's transform.
+func (p *Mob) Render(view, projection mgl32.Mat4, t Transform) {
+	p.Model.Render(view, projection, t, p.AnimState.Pose(p.Model.Def))
+}
+
+func (p *Mob) RenderDepth(setModel func(model mgl32.Mat4), t Transform) {
+	p.Model.RenderDepth(setModel, t, p.AnimState.Pose(p.Model.Def))
+}
@@ -0,0 +1,342 @@
+package entities
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"sort"
+
+	"something/metrics"
+	"something/shader"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// ModelDef is the JSON model format: cuboid parts in a parent/child
+// hierarchy with Blockbench-style box UVs and keyframed animations.
+type ModelDef struct {
+	Texture        string                  `json:"texture"`          // PNG path; empty uses part colours
+	TextureSize    [2]int                  `json:"texture_size"`     // Texture width and height in pixels
+	PixelsPerBlock float32                 `json:"pixels_per_block"` // Texels per block of part size
+	RootOffset     mgl32.Vec3              `json:"root_offset"`      // Root part center relative to the entity's feet
+	Parts          []PartDef               `json:"parts"`            // Parents must precede their children
+	Animations     map[string]AnimationDef `json:"animations"`
+}
+
+// PartDef describes one cuboid.
+type PartDef struct {
+	Name   string     `json:"name"`
+	Parent string     `json:"parent"` // Empty for the root part
+	Size   mgl32.Vec3 `json:"size"`   // Width (x), height (y), depth (z) in blocks
+	Offset mgl32.Vec3 `json:"offset"` // Center relative to the parent's center
+	Pivot  mgl32.Vec3 `json:"pivot"`  // Rotation center relative to the part center
+	UV     [2]int     `json:"uv"`     // Top-left of the part's box UV layout in pixels
+	Color  mgl32.Vec3 `json:"color"`  // Used when the model has no texture
+}
+
+// AnimationDef is a named set of per-part rotation keyframes.
+type AnimationDef struct {
+	Length float32                  `json:"length"` // Seconds
+	Loop   bool                     `json:"loop"`
+	Bones  map[string][]KeyframeDef `json:"bones"` // Part name -> keyframes sorted by time
+}
+
+// KeyframeDef is a part rotation in degrees around X, Y and Z at a time.
+type KeyframeDef struct {
+	Time     float32    `json:"time"`
+	Rotation mgl32.Vec3 `json:"rotation"`
+}
+
+// LoadModelDef reads and validates a model file.
+func LoadModelDef(path string) (*ModelDef, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model: %w", err)
+	}
+	var def ModelDef
+	if err := json.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := def.validate(); err != nil {
+		return nil, fmt.Errorf("invalid model %s: %w", path, err)
+	}
+	return &def, nil
+}
+
+func (d *ModelDef) validate() error {
+	if len(d.Parts) == 0 {
+		return errors.New("model has no parts")
+	}
+	if d.PixelsPerBlock <= 0 {
+		d.PixelsPerBlock = 16
+	}
+	if d.Texture != "" && (d.TextureSize[0] <= 0 || d.TextureSize[1] <= 0) {
+		return errors.New("texture_size is required with a texture")
+	}
+	seen := make(map[string]bool)
+	for i, p := range d.Parts {
+		if p.Name == "" || seen[p.Name] {
+			return fmt.Errorf("part %d: missing or duplicate name %q", i, p.Name)
+		}
+		if p.Parent == "" && i != 0 {
+			return fmt.Errorf("part %q: only the first part may be the root", p.Name)
+		}
+		if p.Parent != "" && !seen[p.Parent] {
+			return fmt.Errorf("part %q: parent %q must be defined earlier", p.Name, p.Parent)
+		}
+		seen[p.Name] = true
+	}
+	for name, anim := range d.Animations {
+		if anim.Length <= 0 {
+			return fmt.Errorf("animation %q: length must be positive", name)
+		}
+		for bone, keys := range anim.Bones {
+			if !seen[bone] {
+				return fmt.Errorf("animation %q: unknown part %q", name, bone)
+			}
+			sort.Slice(keys, func(i, j int) bool { return keys[i].Time < keys[j].Time })
+		}
+	}
+	return nil
+}
+
+// Sample returns each part's rotation in radians at time t, indexed like
+// def.Parts. Looping animations wrap; others hold their last frame.
+func (d *ModelDef) Sample(name string, t float32) []mgl32.Vec3 {
+	pose := make([]mgl32.Vec3, len(d.Parts))
+	anim, ok := d.Animations[name]
+	if !ok {
+		return pose
+	}
+	if anim.Loop {
+		t = float32(math.Mod(float64(t), float64(anim.Length)))
+		if t < 0 {
+			t += anim.Length
+		}
+	} else {
+		t = min(t, anim.Length)
+	}
+	for i, p := range d.Parts {
+		keys := anim.Bones[p.Name]
+		if len(keys) == 0 {
+			continue
+		}
+		rot := keys[len(keys)-1].Rotation
+		for k := 0; k < len(keys); k++ {
+			if t < keys[k].Time {
+				if k == 0 {
+					rot = keys[0].Rotation
+					break
+				}
+				prev := keys[k-1]
+				f := (t - prev.Time) / (keys[k].Time - prev.Time)
+				rot = prev.Rotation.Add(keys[k].Rotation.Sub(prev.Rotation).Mul(f))
+				break
+			}
+		}
+		pose[i] = mgl32.Vec3{mgl32.DegToRad(rot.X()), mgl32.DegToRad(rot.Y()), mgl32.DegToRad(rot.Z())}
+	}
+	return pose
+}
+
+// Model holds the GL resources for a ModelDef.
+type Model struct {
+	Def     *ModelDef
+	Program *shader.Program
+	Texture uint32 // 0 when the model uses part colours
+	Parts   []ModelPart
+}
+
+// ModelPart is the GL mesh of one cuboid.
+type ModelPart struct {
+	VAO, VBO, EBO uint32
+	IndexCount    int32
+	Parent        int // Index of the parent part, -1 for the root
+}
+
+// NewModel uploads a model definition's meshes and texture.
+func NewModel(def *ModelDef) (*Model, error) {
+	program, err := shader.Load("model")
+	if err != nil {
+		return nil, err
+	}
+	m := &Model{Def: def, Program: program}
+	if def.Texture != "" {
+		m.Texture, err = loadModelTexture(def.Texture)
+		if err != nil {
+			m.Cleanup()
+			return nil, err
+		}
+	}
+	index := make(map[string]int)
+	for i, p := range def.Parts {
+		index[p.Name] = i
+		parent := -1
+		if p.Parent != "" {
+			parent = index[p.Parent]
+		}
+		vertices, indices := cuboidMesh(p, def)
+		part := ModelPart{IndexCount: int32(len(indices)), Parent: parent}
+		part.VAO, part.VBO, part.EBO = uploadModelMesh(vertices, indices)
+		m.Parts = append(m.Parts, part)
+	}
+	return m, nil
+}
+
+// Cleanup releases the model's GL resources.
+func (m *Model) Cleanup() {
+	for i := range m.Parts {
+		gl.DeleteVertexArrays(1, &m.Parts[i].VAO)
+		gl.DeleteBuffers(1, &m.Parts[i].VBO)
+		gl.DeleteBuffers(1, &m.Parts[i].EBO)
+	}
+	if m.Texture != 0 {
+		gl.DeleteTextures(1, &m.Texture)
+	}
+	m.Program.Delete()
+}
+
+// Render draws the model at a transform with per-part rotations (radians
+// around X, Y, Z). The model's +X axis faces the transform's yaw.
+func (m *Model) Render(view, projection mgl32.Mat4, t Transform, pose []mgl32.Vec3) {
+	m.Program.Use()
+	m.Program.SetMat4("view", view)
+	m.Program.SetMat4("projection", projection)
+	if m.Texture != 0 {
+		gl.ActiveTexture(gl.TEXTURE0)
+		gl.BindTexture(gl.TEXTURE_2D, m.Texture)
+	}
+	m.Program.SetBool("useTexture", m.Texture != 0)
+
+	for i, joint := range m.joints(t, pose) {
+		m.Program.SetMat4("model", joint)
+		m.Program.SetVec3("partColor", m.Def.Parts[i].Color)
+		gl.BindVertexArray(m.Parts[i].VAO)
+		gl.DrawElements(gl.TRIANGLES, m.Parts[i].IndexCount, gl.UNSIGNED_INT, nil)
+		metrics.Add(metrics.DrawCalls, 1)
+	}
+	gl.BindVertexArray(0)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+}
+
+// RenderDepth draws the posed parts with the caller's depth-only shader.
+func (m *Model) RenderDepth(setModel func(model mgl32.Mat4), t Transform, pose []mgl32.Vec3) {
+	for i, joint := range m.joints(t, pose) {
+		setModel(joint)
+		gl.BindVertexArray(m.Parts[i].VAO)
+		gl.DrawElements(gl.TRIANGLES, m.Parts[i].IndexCount, gl.UNSIGNED_INT, nil)
+		metrics.Add(metrics.DrawCalls, 1)
+	}
+}
+
+// joints returns each part's model matrix for a transform and pose.
+func (m *Model) joints(t Transform, pose []mgl32.Vec3) []mgl32.Mat4 {
+	root := mgl32.Translate3D(t.Position.X(), t.Position.Y(), t.Position.Z()).
+		Mul4(mgl32.HomogRotate3DY(mgl32.DegToRad(-t.Yaw))).
+		Mul4(mgl32.Translate3D(m.Def.RootOffset.X(), m.Def.RootOffset.Y(), m.Def.RootOffset.Z()))
+	joints := make([]mgl32.Mat4, len(m.Parts))
+	for i, part := range m.Parts {
+		def := m.Def.Parts[i]
+		parent := root
+		if part.Parent >= 0 {
+			parent = joints[part.Parent]
+		}
+		var rot mgl32.Vec3
+		if i < len(pose) {
+			rot = pose[i]
+		}
+		joints[i] = parent.Mul4(mgl32.Translate3D(def.Offset.X(), def.Offset.Y(), def.Offset.Z())).
+			Mul4(rotateAround(def.Pivot, rot))
+	}
+	return joints
+}
+
+// cuboidMesh builds a part's vertices (position, uv) centered on the part,
+// using the box UV layout:
+//
+//	        [ top ][bottom]
+//	[ -X  ][ +Z  ][ +X  ][ -Z  ]
+func cuboidMesh(p PartDef, def *ModelDef) ([]float32, []uint32) {
+	hx, hy, hz := p.Size.X()/2, p.Size.Y()/2, p.Size.Z()/2
+	w, h, d := p.Size.X()*def.PixelsPerBlock, p.Size.Y()*def.PixelsPerBlock, p.Size.Z()*def.PixelsPerBlock
+	u, v := float32(p.UV[0]), float32(p.UV[1])
+	type face struct {
+		corners [4]mgl32.Vec3 // Bottom-left, bottom-right, top-right, top-left seen from outside
+		rect    [4]float32    // u, v, width, height in pixels
+	}
+	faces := []face{
+		{[4]mgl32.Vec3{{-hx, -hy, hz}, {hx, -hy, hz}, {hx, hy, hz}, {-hx, hy, hz}}, [4]float32{u + d, v + d, w, h}},
+		{[4]mgl32.Vec3{{hx, -hy, -hz}, {-hx, -hy, -hz}, {-hx, hy, -hz}, {hx, hy, -hz}}, [4]float32{u + 2*d + w, v + d, w, h}},
+		{[4]mgl32.Vec3{{hx, -hy, hz}, {hx, -hy, -hz}, {hx, hy, -hz}, {hx, hy, hz}}, [4]float32{u + d + w, v + d, d, h}},
+		{[4]mgl32.Vec3{{-hx, -hy, -hz}, {-hx, -hy, hz}, {-hx, hy, hz}, {-hx, hy, -hz}}, [4]float32{u, v + d, d, h}},
+		{[4]mgl32.Vec3{{-hx, hy, hz}, {hx, hy, hz}, {hx, hy, -hz}, {-hx, hy, -hz}}, [4]float32{u + d, v, w, d}},
+		{[4]mgl32.Vec3{{-hx, -hy, -hz}, {hx, -hy, -hz}, {hx, -hy, hz}, {-hx, -hy, hz}}, [4]float32{u + d + w, v, w, d}},
+	}
+	texW, texH := float32(max(def.TextureSize[0], 1)), float32(max(def.TextureSize[1], 1))
+	var vertices []float32
+	var indices []uint32
+	for i, f := range faces {
+		r := f.rect
+		uvs := [4][2]float32{
+			{r[0] / texW, (r[1] + r[3]) / texH},
+			{(r[0] + r[2]) / texW, (r[1] + r[3]) / texH},
+			{(r[0] + r[2]) / texW, r[1] / texH},
+			{r[0] / texW, r[1] / texH},
+		}
+		for c := 0; c < 4; c++ {
+			vertices = append(vertices, f.corners[c].X(), f.corners[c].Y(), f.corners[c].Z(), uvs[c][0], uvs[c][1])
+		}
+		base := uint32(i * 4)
+		indices = append(indices, base, base+1, base+2, base+2, base+3, base)
+	}
+	return vertices, indices
+}
+
+func uploadModelMesh(vertices []float32, indices []uint32) (vao, vbo, ebo uint32) {
+	gl.GenVertexArrays(1, &vao)
+	gl.GenBuffers(1, &vbo)
+	gl.GenBuffers(1, &ebo)
+	gl.BindVertexArray(vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.STATIC_DRAW)
+	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 5*4, gl.PtrOffset(0))
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(1, 2, gl.FLOAT, false, 5*4, gl.PtrOffset(3*4))
+	gl.EnableVertexAttribArray(1)
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
+	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, len(indices)*4, gl.Ptr(indices), gl.STATIC_DRAW)
+	gl.BindVertexArray(0)
+	return vao, vbo, ebo
+}
+
+// loadModelTexture uploads a PNG with its top row at v = 0, matching the
+// pixel coordinates used by box UVs.
+func loadModelTexture(path string) (uint32, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open texture at %s: %v", path, err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode PNG at %s: %v", path, err)
+	}
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	var texture uint32
+	gl.GenTextures(1, &texture)
+	gl.BindTexture(gl.TEXTURE_2D, texture)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(rgba.Rect.Size().X), int32(rgba.Rect.Size().Y),
+		0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(rgba.Pix))
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	return texture, nil
+}
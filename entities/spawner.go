@@ -0,0 +1,304 @@
+package entities
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"something/block"
+	"something/pathfind"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// SpawnRule describes where and how often a mob type appears in newly
+// generated chunks.
+type SpawnRule struct {
+	Biomes   []string `json:"biomes"`    // Allowed biomes; empty allows any
+	Blocks   []string `json:"blocks"`    // Allowed surface block names; empty allows any
+	MinLight int      `json:"min_light"` // Lowest light level at the feet
+	MaxLight int      `json:"max_light"` // Highest light level at the feet; 0 means no limit
+	Chance   float32  `json:"chance"`    // Probability of a group per new chunk
+	GroupMin int      `json:"group_min"`
+	GroupMax int      `json:"group_max"`
+	Cap      int      `json:"cap"`     // Max loaded entities of the type; 0 means no limit
+	Despawn  bool     `json:"despawn"` // Whether far-away entities may despawn
+}
+
+// SpawnWorld is the terrain information spawn rules are checked against;
+// *world.World implements it.
+type SpawnWorld interface {
+	Terrain
+	GetBlock(x, y, z int) block.BlockID
+	Biome(x, z int) string
+	Light(x, y, z int) int
+}
+
+const (
+	defaultDespawnDistance = 48.0     // Horizontal blocks from the player
+	despawnChance          = 1.0 / 30 // Per second for each entity beyond the distance
+	groupSpread            = 3        // Max blocks between group members and the group center
+)
+
+// SpawnStats counts spawner activity for the debug overlay.
+type SpawnStats struct {
+	ChunksPopulated int
+	Spawned         map[string]int // Type -> entities spawned
+	Despawned       map[string]int // Type -> entities despawned
+	Rejected        map[string]int // Reason -> rejected spawn attempts
+}
+
+// Spawner populates new chunks with mobs and despawns mobs far from the
+// player. Initial population uses a random source derived from the world
+// seed and chunk position, so the same seed gives the same mobs.
+type Spawner struct {
+	Manager         *Manager
+	World           SpawnWorld
+	Seed            int64
+	DespawnDistance float32
+	Stats           SpawnStats
+
+	rules     map[string]spawnType
+	populated map[[2]int]bool
+	rng       *rand.Rand // Despawn rolls
+	tick      float32
+}
+
+type spawnType struct {
+	rule  SpawnRule
+	agent pathfind.Agent
+}
+
+// NewSpawner creates a spawner with no rules.
+func NewSpawner(m *Manager, w SpawnWorld, seed int64) *Spawner {
+	return &Spawner{
+		Manager:         m,
+		World:           w,
+		Seed:            seed,
+		DespawnDistance: defaultDespawnDistance,
+		Stats: SpawnStats{
+			Spawned:   make(map[string]int),
+			Despawned: make(map[string]int),
+			Rejected:  make(map[string]int),
+		},
+		rules:     make(map[string]spawnType),
+		populated: make(map[[2]int]bool),
+		rng:       rand.New(rand.NewSource(seed)),
+	}
+}
+
+// AddMobs adds the spawn rules of every definition that has one.
+func (s *Spawner) AddMobs(defs []MobDef) {
+	for _, def := range defs {
+		if def.Spawn != nil {
+			s.rules[def.Name] = spawnType{rule: *def.Spawn, agent: def.Agent()}
+		}
+	}
+}
+
+// Populated reports whether a chunk has been populated, which happens the
+// first time it is generated.
+func (s *Spawner) Populated(x, z int) bool {
+	return s.populated[[2]int{x, z}]
+}
+
+// PopulateChunk spawns the initial mobs of a chunk the first time it is
+// generated. It returns how many entities were spawned.
+func (s *Spawner) PopulateChunk(x, z int) (int, error) {
+	key := [2]int{x, z}
+	if s.populated[key] {
+		return 0, nil
+	}
+	s.populated[key] = true
+	s.Stats.ChunksPopulated++
+	rng := rand.New(rand.NewSource(chunkSeed(s.Seed, x, z)))
+	names := make([]string, 0, len(s.rules))
+	for name := range s.rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	// Decide every spawn from the chunk's seed alone, then apply caps, which
+	// depend on what happens to be loaded, only to drop planned spawns.
+	type plannedSpawn struct {
+		name string
+		t    Transform
+	}
+	var planned []plannedSpawn
+	for _, name := range names {
+		st := s.rules[name]
+		// Roll every random value up front so a rejected rule does not shift
+		// the sequence used by later rules.
+		roll := rng.Float32()
+		cx, cz := x*ChunkSize+rng.Intn(ChunkSize), z*ChunkSize+rng.Intn(ChunkSize)
+		size := st.rule.GroupMin
+		if st.rule.GroupMax > st.rule.GroupMin {
+			size += rng.Intn(st.rule.GroupMax - st.rule.GroupMin + 1)
+		}
+		offsets := make([][2]int, max(size, 1))
+		yaws := make([]float32, len(offsets))
+		for i := range offsets {
+			offsets[i] = [2]int{rng.Intn(2*groupSpread+1) - groupSpread, rng.Intn(2*groupSpread+1) - groupSpread}
+			yaws[i] = rng.Float32() * 360
+		}
+		if roll >= st.rule.Chance {
+			continue
+		}
+		if reason := s.checkColumn(st.rule, cx, cz); reason != "" {
+			s.Stats.Rejected[reason]++
+			continue
+		}
+		used := make(map[[2]int]bool)
+		for i := 0; i < size; i++ {
+			// Keep members inside the chunk, whose terrain is known to be loaded
+			mx := min(max(cx+offsets[i][0], x*ChunkSize), x*ChunkSize+ChunkSize-1)
+			mz := min(max(cz+offsets[i][1], z*ChunkSize), z*ChunkSize+ChunkSize-1)
+			y, ok := surface(s.World, mx, mz)
+			if !ok || used[[2]int{mx, mz}] || !st.agent.CanStand(s.World, pathfind.Pos{mx, y, mz}) {
+				s.Stats.Rejected["space"]++
+				continue
+			}
+			used[[2]int{mx, mz}] = true
+			pos := mgl32.Vec3{float32(mx) + 0.5, float32(y), float32(mz) + 0.5}
+			planned = append(planned, plannedSpawn{name, Transform{Position: pos, Yaw: yaws[i]}})
+		}
+	}
+	spawned := 0
+	for _, p := range planned {
+		if limit := s.rules[p.name].rule.Cap; limit > 0 && s.Manager.CountType(p.name) >= limit {
+			s.Stats.Rejected["cap"]++
+			continue
+		}
+		if _, err := s.Manager.Spawn(p.name, p.t); err != nil {
+			return spawned, fmt.Errorf("failed to spawn %s: %w", p.name, err)
+		}
+		s.Stats.Spawned[p.name]++
+		spawned++
+	}
+	return spawned, nil
+}
+
+// checkColumn returns why a rule cannot spawn at the column, or "".
+func (s *Spawner) checkColumn(r SpawnRule, x, z int) string {
+	y, ok := surface(s.World, x, z)
+	if !ok {
+		return "space"
+	}
+	if len(r.Biomes) > 0 && !slices.Contains(r.Biomes, s.World.Biome(x, z)) {
+		return "biome"
+	}
+	if len(r.Blocks) > 0 && !slices.Contains(r.Blocks, block.Names[s.World.GetBlock(x, y-1, z)]) {
+		return "block"
+	}
+	light := s.World.Light(x, y, z)
+	if light < r.MinLight || (r.MaxLight > 0 && light > r.MaxLight) {
+		return "light"
+	}
+	return ""
+}
+
+// Update despawns mobs whose rule allows it once they are far from the player.
+func (s *Spawner) Update(dt float32) {
+	s.tick += dt
+	if s.tick < 1 {
+		return
+	}
+	s.tick--
+	for _, id := range s.Manager.IDs() {
+		name := s.Manager.Types[id]
+		st, ok := s.rules[name]
+		if !ok || !st.rule.Despawn {
+			continue
+		}
+		d := s.Manager.Transforms[id].Position.Sub(s.Manager.Player.Position)
+		d[1] = 0
+		if d.Len() > s.DespawnDistance && s.rng.Float32() < despawnChance {
+			s.Manager.Remove(id)
+			s.Stats.Despawned[name]++
+		}
+	}
+}
+
+// Lines formats the spawner's stats for the debug overlay.
+func (s *Spawner) Lines() []string {
+	lines := []string{fmt.Sprintf("Chunks populated: %d", s.Stats.ChunksPopulated)}
+	names := make([]string, 0, len(s.rules))
+	for name := range s.rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("%s: %d loaded, %d spawned, %d despawned",
+			name, s.Manager.CountType(name), s.Stats.Spawned[name], s.Stats.Despawned[name]))
+	}
+	reasons := make([]string, 0, len(s.Stats.Rejected))
+	for reason := range s.Stats.Rejected {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		lines = append(lines, fmt.Sprintf("Rejected (%s): %d", reason, s.Stats.Rejected[reason]))
+	}
+	return lines
+}
+
+// Load reads the set of already populated chunks. A missing file is not an
+// error.
+func (s *Spawner) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read spawner state: %w", err)
+	}
+	var chunks [][2]int
+	if err := json.Unmarshal(data, &chunks); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for _, key := range chunks {
+		s.populated[key] = true
+	}
+	return nil
+}
+
+// Save writes the set of populated chunks.
+func (s *Spawner) Save(path string) error {
+	chunks := make([][2]int, 0, len(s.populated))
+	for key := range s.populated {
+		chunks = append(chunks, key)
+	}
+	sort.Slice(chunks, func(i, j int) bool {
+		if chunks[i][0] != chunks[j][0] {
+			return chunks[i][0] < chunks[j][0]
+		}
+		return chunks[i][1] < chunks[j][1]
+	})
+	data, err := json.Marshal(chunks)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create spawner directory: %w", err)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// surface returns the feet position above the highest solid block of a
+// column.
+func surface(w SpawnWorld, x, z int) (int, bool) {
+	for y := ChunkSize - 1; y >= 0; y-- {
+		if w.IsSolid(x, y, z) {
+			return y + 1, y+1 < ChunkSize
+		}
+	}
+	return 0, false
+}
+
+// chunkSeed mixes the world seed with chunk coordinates.
+func chunkSeed(seed int64, x, z int) int64 {
+	return seed ^ int64(x)*341873128712 ^ int64(z)*132897987541
+}
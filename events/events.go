@@ -0,0 +1,56 @@
+package events
+
+import "sync"
+
+// Type identifies a kind of game event.
+type Type string
+
+const (
+	BlockBroken         Type = "block_broken"
+	BlockPlaced         Type = "block_placed"
+	DistanceTravelled   Type = "distance_travelled"
+	ItemCrafted         Type = "item_crafted"
+	PonyInteracted      Type = "pony_interacted"
+	PlayerJumped        Type = "player_jumped"
+	PlayerDied          Type = "player_died"
+	ChunkGenerated      Type = "chunk_generated"
+	AchievementUnlocked Type = "achievement_unlocked"
+)
+
+// Event is a single game occurrence published on a Bus.
+type Event struct {
+	Type    Type
+	Subject string  // What the event is about (block name, item name, achievement ID)
+	Amount  float64 // Quantity, e.g. blocks or distance in blocks
+}
+
+// Handler receives published events.
+type Handler func(Event)
+
+// Bus dispatches events synchronously to subscribers.
+type Bus struct {
+	mu       sync.Mutex
+	handlers map[Type][]Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[Type][]Handler)}
+}
+
+// Subscribe registers h for events of type t.
+func (b *Bus) Subscribe(t Type, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[t] = append(b.handlers[t], h)
+}
+
+// Publish delivers e to every subscriber of its type. Handlers may publish
+// further events.
+func (b *Bus) Publish(e Event) {
+	b.mu.Lock()
+	handlers := append([]Handler(nil), b.handlers[e.Type]...)
+	b.mu.Unlock()
+	for _, h := range handlers {
+		h(e)
+	}
+}
@@ -1,11 +1,254 @@
 package inventory
 
-import "something/block"
+import (
+	"fmt"
 
+	"something/block"
+	"something/item"
+)
+
+const (
+	Size       = 36 // Total number of slots
+	HotbarSize = 9  // Slots 0-8 form the hotbar
+)
+
+// Inventory holds item stacks; the first HotbarSize slots are the hotbar.
 type Inventory struct {
-	Slots []block.Block
+	Slots    []item.ItemStack
+	Selected int // Selected hotbar slot (0-8)
 }
 
 func NewInventory() *Inventory {
-	return &Inventory{Slots: make([]block.Block, 36)}
+	return &Inventory{Slots: make([]item.ItemStack, Size)}
+}
+
+// Clone returns a deep copy of the inventory.
+func (inv *Inventory) Clone() *Inventory {
+	c := &Inventory{Slots: make([]item.ItemStack, len(inv.Slots)), Selected: inv.Selected}
+	for i, s := range inv.Slots {
+		s.Metadata = cloneMetadata(s.Metadata)
+		c.Slots[i] = s
+	}
+	return c
+}
+
+// Add inserts a stack, topping up matching stacks before using empty slots.
+// It returns the number of items that did not fit.
+func (inv *Inventory) Add(stack item.ItemStack) int {
+	if stack.IsEmpty() {
+		return 0
+	}
+	if stack.MaxStack <= 0 {
+		stack.MaxStack = item.NewStack(stack.ID, 0).MaxStack
+	}
+	remaining := stack.Count
+	for i := range inv.Slots {
+		if remaining == 0 {
+			return 0
+		}
+		slot := &inv.Slots[i]
+		if !slot.CanStackWith(stack) {
+			continue
+		}
+		n := min(remaining, slot.Space())
+		slot.Count += n
+		remaining -= n
+	}
+	for i := range inv.Slots {
+		if remaining == 0 {
+			return 0
+		}
+		if !inv.Slots[i].IsEmpty() {
+			continue
+		}
+		placed := stack
+		placed.Count = min(remaining, stack.MaxStack)
+		placed.Metadata = cloneMetadata(stack.Metadata)
+		inv.Slots[i] = placed
+		remaining -= placed.Count
+	}
+	return remaining
+}
+
+// Remove takes up to count items of the given type, last slots first so the
+// hotbar is drained last. It returns the number of items removed.
+func (inv *Inventory) Remove(id item.ItemID, count int) int {
+	removed := 0
+	for i := len(inv.Slots) - 1; i >= 0 && removed < count; i-- {
+		slot := &inv.Slots[i]
+		if slot.IsEmpty() || slot.ID != id {
+			continue
+		}
+		n := min(count-removed, slot.Count)
+		slot.Count -= n
+		removed += n
+		if slot.Count == 0 {
+			*slot = item.ItemStack{}
+		}
+	}
+	return removed
+}
+
+// RemoveFromSlot takes up to count items from one slot and returns them.
+func (inv *Inventory) RemoveFromSlot(slot, count int) (item.ItemStack, error) {
+	if err := inv.checkSlot(slot); err != nil {
+		return item.ItemStack{}, err
+	}
+	s := &inv.Slots[slot]
+	if s.IsEmpty() || count <= 0 {
+		return item.ItemStack{}, nil
+	}
+	taken := *s
+	taken.Count = min(count, s.Count)
+	taken.Metadata = cloneMetadata(s.Metadata)
+	s.Count -= taken.Count
+	if s.Count == 0 {
+		*s = item.ItemStack{}
+	}
+	return taken, nil
+}
+
+// Count returns the total number of items of the given type.
+func (inv *Inventory) Count(id item.ItemID) int {
+	total := 0
+	for _, s := range inv.Slots {
+		if !s.IsEmpty() && s.ID == id {
+			total += s.Count
+		}
+	}
+	return total
+}
+
+// Split removes the larger half of a slot's stack and returns it.
+func (inv *Inventory) Split(slot int) (item.ItemStack, error) {
+	if err := inv.checkSlot(slot); err != nil {
+		return item.ItemStack{}, err
+	}
+	return inv.RemoveFromSlot(slot, (inv.Slots[slot].Count+1)/2)
+}
+
+// Merge moves as many items as fit from one slot onto another. An empty
+// destination receives the whole stack.
+func (inv *Inventory) Merge(from, to int) error {
+	if err := inv.checkSlot(from); err != nil {
+		return err
+	}
+	if err := inv.checkSlot(to); err != nil {
+		return err
+	}
+	src, dst := &inv.Slots[from], &inv.Slots[to]
+	if from == to || src.IsEmpty() {
+		return nil
+	}
+	if dst.IsEmpty() {
+		*dst, *src = *src, item.ItemStack{}
+		return nil
+	}
+	if !dst.CanStackWith(*src) {
+		return fmt.Errorf("cannot merge slot %d into slot %d: items differ", from, to)
+	}
+	n := min(src.Count, dst.Space())
+	dst.Count += n
+	src.Count -= n
+	if src.Count == 0 {
+		*src = item.ItemStack{}
+	}
+	return nil
+}
+
+// Swap exchanges the contents of two slots.
+func (inv *Inventory) Swap(a, b int) error {
+	if err := inv.checkSlot(a); err != nil {
+		return err
+	}
+	if err := inv.checkSlot(b); err != nil {
+		return err
+	}
+	inv.Slots[a], inv.Slots[b] = inv.Slots[b], inv.Slots[a]
+	return nil
+}
+
+// QuickMove shifts a stack between the hotbar and the main inventory,
+// merging into matching stacks first. Items that do not fit stay put.
+func (inv *Inventory) QuickMove(slot int) error {
+	if err := inv.checkSlot(slot); err != nil {
+		return err
+	}
+	if inv.Slots[slot].IsEmpty() {
+		return nil
+	}
+	start, end := HotbarSize, len(inv.Slots)
+	if slot >= HotbarSize {
+		start, end = 0, HotbarSize
+	}
+	for _, wantEmpty := range []bool{false, true} {
+		for i := start; i < end && !inv.Slots[slot].IsEmpty(); i++ {
+			if inv.Slots[i].IsEmpty() != wantEmpty {
+				continue
+			}
+			if !wantEmpty && !inv.Slots[i].CanStackWith(inv.Slots[slot]) {
+				continue
+			}
+			if err := inv.Merge(slot, i); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Select sets the active hotbar slot.
+func (inv *Inventory) Select(slot int) error {
+	if slot < 0 || slot >= HotbarSize {
+		return fmt.Errorf("hotbar slot %d out of range", slot)
+	}
+	inv.Selected = slot
+	return nil
+}
+
+// Scroll moves the hotbar selection by delta, wrapping around.
+func (inv *Inventory) Scroll(delta int) {
+	inv.Selected = ((inv.Selected+delta)%HotbarSize + HotbarSize) % HotbarSize
+}
+
+// SelectedStack returns the stack in the active hotbar slot.
+func (inv *Inventory) SelectedStack() item.ItemStack {
+	return inv.Slots[inv.Selected]
+}
+
+// SelectedBlock returns the block placed by the active hotbar item, if any.
+func (inv *Inventory) SelectedBlock() (block.BlockID, bool) {
+	s := inv.SelectedStack()
+	if s.IsEmpty() {
+		return block.BlockAir, false
+	}
+	it, ok := item.Items[s.ID]
+	if !ok || !it.Placeable {
+		return block.BlockAir, false
+	}
+	return it.Block, true
+}
+
+// ConsumeSelected removes one item from the active hotbar slot.
+func (inv *Inventory) ConsumeSelected() bool {
+	taken, _ := inv.RemoveFromSlot(inv.Selected, 1)
+	return !taken.IsEmpty()
+}
+
+func (inv *Inventory) checkSlot(slot int) error {
+	if slot < 0 || slot >= len(inv.Slots) {
+		return fmt.Errorf("slot %d out of range", slot)
+	}
+	return nil
+}
+
+func cloneMetadata(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
 }
@@ -0,0 +1,286 @@
+package inventory
+
+import (
+	"testing"
+
+	"something/item"
+)
+
+// fill returns an inventory with the given slots set.
+func fill(slots map[int]item.ItemStack) *Inventory {
+	inv := NewInventory()
+	for i, s := range slots {
+		inv.Slots[i] = s
+	}
+	return inv
+}
+
+func stone(n int) item.ItemStack { return item.NewStack(item.ItemStone, n) }
+func dirt(n int) item.ItemStack  { return item.NewStack(item.ItemDirt, n) }
+
+func TestAdd(t *testing.T) {
+	full := make(map[int]item.ItemStack, Size)
+	for i := 0; i < Size; i++ {
+		full[i] = dirt(item.DefaultMaxStack)
+	}
+	full[Size-1] = stone(60)
+
+	tests := []struct {
+		name     string
+		slots    map[int]item.ItemStack
+		add      item.ItemStack
+		leftover int
+		want     map[int]int // Slot -> expected count
+	}{
+		{"empty inventory", nil, stone(10), 0, map[int]int{0: 10}},
+		{"tops up matching stack first", map[int]item.ItemStack{3: stone(60)}, stone(10), 0, map[int]int{0: 6, 3: 64}},
+		{"splits over max stack", nil, stone(100), 0, map[int]int{0: 64, 1: 36}},
+		{"skips other items", map[int]item.ItemStack{0: dirt(5)}, stone(5), 0, map[int]int{0: 5, 1: 5}},
+		{"returns overflow", full, stone(10), 6, map[int]int{Size - 1: 64}},
+		{"empty stack", nil, item.ItemStack{}, 0, map[int]int{0: 0}},
+		{"fills in missing max stack", nil, item.ItemStack{ID: item.ItemStonePickaxe, Count: 2}, 0, map[int]int{0: 1, 1: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := fill(tt.slots)
+			if got := inv.Add(tt.add); got != tt.leftover {
+				t.Errorf("Add returned %d, want %d", got, tt.leftover)
+			}
+			for slot, count := range tt.want {
+				if got := inv.Slots[slot].Count; got != count {
+					t.Errorf("slot %d has %d, want %d", slot, got, count)
+				}
+			}
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	tests := []struct {
+		name    string
+		slots   map[int]item.ItemStack
+		count   int
+		removed int
+		want    map[int]int
+	}{
+		{"drains last slots first", map[int]item.ItemStack{0: stone(10), 20: stone(10)}, 15, 15, map[int]int{0: 5, 20: 0}},
+		{"removes what there is", map[int]item.ItemStack{4: stone(3)}, 10, 3, map[int]int{4: 0}},
+		{"ignores other items", map[int]item.ItemStack{0: dirt(5)}, 5, 0, map[int]int{0: 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := fill(tt.slots)
+			if got := inv.Remove(item.ItemStone, tt.count); got != tt.removed {
+				t.Errorf("Remove returned %d, want %d", got, tt.removed)
+			}
+			for slot, count := range tt.want {
+				if got := inv.Slots[slot].Count; got != count {
+					t.Errorf("slot %d has %d, want %d", slot, got, count)
+				}
+				if count == 0 && inv.Slots[slot].ID != item.ItemNone {
+					t.Errorf("slot %d was not cleared", slot)
+				}
+			}
+		})
+	}
+}
+
+func TestRemoveFromSlot(t *testing.T) {
+	tests := []struct {
+		name    string
+		slot    int
+		count   int
+		taken   int
+		left    int
+		wantErr bool
+	}{
+		{"part of stack", 2, 3, 3, 7, false},
+		{"more than stack", 2, 20, 10, 0, false},
+		{"zero count", 2, 0, 0, 10, false},
+		{"empty slot", 5, 1, 0, 10, false},
+		{"out of range", Size, 1, 0, 10, true},
+		{"negative slot", -1, 1, 0, 10, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := fill(map[int]item.ItemStack{2: stone(10)})
+			taken, err := inv.RemoveFromSlot(tt.slot, tt.count)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RemoveFromSlot error = %v, want error %v", err, tt.wantErr)
+			}
+			if taken.Count != tt.taken {
+				t.Errorf("took %d, want %d", taken.Count, tt.taken)
+			}
+			if got := inv.Slots[2].Count; got != tt.left {
+				t.Errorf("slot has %d left, want %d", got, tt.left)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		count, taken, left int
+	}{
+		{10, 5, 5},
+		{7, 4, 3},
+		{1, 1, 0},
+		{0, 0, 0},
+	}
+	for _, tt := range tests {
+		inv := fill(map[int]item.ItemStack{0: stone(tt.count)})
+		taken, err := inv.Split(0)
+		if err != nil {
+			t.Fatalf("Split(%d): %v", tt.count, err)
+		}
+		if taken.Count != tt.taken || inv.Slots[0].Count != tt.left {
+			t.Errorf("Split(%d) = %d taken, %d left; want %d, %d", tt.count, taken.Count, inv.Slots[0].Count, tt.taken, tt.left)
+		}
+	}
+	if _, err := NewInventory().Split(Size); err == nil {
+		t.Error("Split out of range succeeded")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name       string
+		from, to   item.ItemStack
+		wantFrom   int
+		wantTo     int
+		wantErr    bool
+		wantToItem item.ItemID
+	}{
+		{"into empty", stone(10), item.ItemStack{}, 0, 10, false, item.ItemStone},
+		{"all fits", stone(10), stone(20), 0, 30, false, item.ItemStone},
+		{"clamps to max stack", stone(30), stone(50), 16, 64, false, item.ItemStone},
+		{"onto full stack", stone(5), stone(64), 5, 64, false, item.ItemStone},
+		{"different items", stone(5), dirt(5), 5, 5, true, item.ItemDirt},
+		{"from empty", item.ItemStack{}, dirt(5), 0, 5, false, item.ItemDirt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := fill(map[int]item.ItemStack{0: tt.from, 1: tt.to})
+			err := inv.Merge(0, 1)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Merge error = %v, want error %v", err, tt.wantErr)
+			}
+			if inv.Slots[0].Count != tt.wantFrom || inv.Slots[1].Count != tt.wantTo {
+				t.Errorf("got %d -> %d, want %d -> %d", inv.Slots[0].Count, inv.Slots[1].Count, tt.wantFrom, tt.wantTo)
+			}
+			if inv.Slots[1].ID != tt.wantToItem {
+				t.Errorf("destination holds item %d, want %d", inv.Slots[1].ID, tt.wantToItem)
+			}
+		})
+	}
+	inv := fill(map[int]item.ItemStack{0: stone(5)})
+	if err := inv.Merge(0, 0); err != nil || inv.Slots[0].Count != 5 {
+		t.Errorf("Merge onto itself = %v with %d left, want no-op", err, inv.Slots[0].Count)
+	}
+	if err := inv.Merge(0, Size); err == nil {
+		t.Error("Merge out of range succeeded")
+	}
+}
+
+func TestSwap(t *testing.T) {
+	inv := fill(map[int]item.ItemStack{0: stone(3), 30: dirt(7)})
+	if err := inv.Swap(0, 30); err != nil {
+		t.Fatal(err)
+	}
+	if inv.Slots[0].ID != item.ItemDirt || inv.Slots[0].Count != 7 || inv.Slots[30].ID != item.ItemStone || inv.Slots[30].Count != 3 {
+		t.Errorf("Swap gave %+v and %+v", inv.Slots[0], inv.Slots[30])
+	}
+	if err := inv.Swap(-1, 0); err == nil {
+		t.Error("Swap out of range succeeded")
+	}
+}
+
+func TestQuickMove(t *testing.T) {
+	tests := []struct {
+		name  string
+		slots map[int]item.ItemStack
+		slot  int
+		want  map[int]int
+	}{
+		{"hotbar to first main slot", map[int]item.ItemStack{0: stone(10)}, 0, map[int]int{0: 0, HotbarSize: 10}},
+		{"main to first hotbar slot", map[int]item.ItemStack{20: stone(10)}, 20, map[int]int{20: 0, 0: 10}},
+		{"merges into matching stack before empty slots", map[int]item.ItemStack{0: stone(10), 15: stone(60)}, 0, map[int]int{0: 0, HotbarSize: 6, 15: 64}},
+		{"skips occupied hotbar slots", map[int]item.ItemStack{0: dirt(1), 1: dirt(1), 20: stone(10)}, 20, map[int]int{2: 10, 20: 0}},
+		{"empty slot", nil, 3, map[int]int{3: 0, HotbarSize: 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := fill(tt.slots)
+			if err := inv.QuickMove(tt.slot); err != nil {
+				t.Fatal(err)
+			}
+			for slot, count := range tt.want {
+				if got := inv.Slots[slot].Count; got != count {
+					t.Errorf("slot %d has %d, want %d", slot, got, count)
+				}
+			}
+		})
+	}
+
+	t.Run("leftover stays put", func(t *testing.T) {
+		slots := map[int]item.ItemStack{0: stone(10)}
+		for i := HotbarSize; i < Size; i++ {
+			slots[i] = dirt(1)
+		}
+		slots[Size-1] = stone(60)
+		inv := fill(slots)
+		if err := inv.QuickMove(0); err != nil {
+			t.Fatal(err)
+		}
+		if inv.Slots[0].Count != 6 || inv.Slots[Size-1].Count != 64 {
+			t.Errorf("got %d left and %d moved, want 6 and 64", inv.Slots[0].Count, inv.Slots[Size-1].Count)
+		}
+	})
+}
+
+func TestSelectAndScroll(t *testing.T) {
+	inv := NewInventory()
+	for _, slot := range []int{-1, HotbarSize} {
+		if err := inv.Select(slot); err == nil {
+			t.Errorf("Select(%d) succeeded", slot)
+		}
+	}
+	tests := []struct {
+		from, delta, want int
+	}{
+		{0, 1, 1},
+		{8, 1, 0},
+		{0, -1, 8},
+		{4, 18, 4},
+		{2, -12, 8},
+	}
+	for _, tt := range tests {
+		if err := inv.Select(tt.from); err != nil {
+			t.Fatal(err)
+		}
+		inv.Scroll(tt.delta)
+		if inv.Selected != tt.want {
+			t.Errorf("Scroll(%d) from %d = %d, want %d", tt.delta, tt.from, inv.Selected, tt.want)
+		}
+	}
+}
+
+func TestConsumeSelected(t *testing.T) {
+	inv := fill(map[int]item.ItemStack{1: stone(1)})
+	if inv.ConsumeSelected() {
+		t.Error("ConsumeSelected on an empty slot succeeded")
+	}
+	inv.Select(1)
+	if !inv.ConsumeSelected() {
+		t.Error("ConsumeSelected failed")
+	}
+	if !inv.Slots[1].IsEmpty() {
+		t.Errorf("slot still holds %+v", inv.Slots[1])
+	}
+	if inv.ConsumeSelected() {
+		t.Error("ConsumeSelected on the emptied slot succeeded")
+	}
+	if _, ok := inv.SelectedBlock(); ok {
+		t.Error("SelectedBlock reports a block for an empty slot")
+	}
+}
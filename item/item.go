@@ -1 +1,110 @@
 package item
+
+import "something/block"
+
+// ItemID represents an item type identifier.
+type ItemID uint16
+
+// Block items share their numeric value with the block they place.
+const (
+	ItemNone  ItemID = ItemID(block.BlockAir)
+	ItemGrass ItemID = ItemID(block.BlockGrass)
+	ItemDirt  ItemID = ItemID(block.BlockDirt)
+	ItemStone ItemID = ItemID(block.BlockStone)
+)
+
+// Non-block items start above the block ID range.
+const (
+	ItemPebble ItemID = 256 + iota
+	ItemStonePickaxe
+	ItemStoneShovel
+)
+
+// DefaultMaxStack is the stack limit used by most items.
+const DefaultMaxStack = 64
+
+// Item describes an item type.
+type Item struct {
+	ID        ItemID
+	Name      string        // Unique lowercase name, used by data files
+	MaxStack  int           // Maximum count per slot
+	Block     block.BlockID // Block placed by this item
+	Placeable bool          // Whether the item places Block
+}
+
+// Items maps ItemIDs to their definitions.
+var Items = map[ItemID]Item{
+	ItemGrass: {ID: ItemGrass, Name: "grass", MaxStack: DefaultMaxStack, Block: block.BlockGrass, Placeable: true},
+	ItemDirt:  {ID: ItemDirt, Name: "dirt", MaxStack: DefaultMaxStack, Block: block.BlockDirt, Placeable: true},
+	ItemStone: {ID: ItemStone, Name: "stone", MaxStack: DefaultMaxStack, Block: block.BlockStone, Placeable: true},
+
+	ItemPebble:       {ID: ItemPebble, Name: "pebble", MaxStack: DefaultMaxStack},
+	ItemStonePickaxe: {ID: ItemStonePickaxe, Name: "stone_pickaxe", MaxStack: 1},
+	ItemStoneShovel:  {ID: ItemStoneShovel, Name: "stone_shovel", MaxStack: 1},
+}
+
+// ByName looks up an item by its registry name.
+func ByName(name string) (ItemID, bool) {
+	for id, it := range Items {
+		if it.Name == name {
+			return id, true
+		}
+	}
+	return ItemNone, false
+}
+
+// FromBlock returns the item dropped when a block is broken.
+func FromBlock(id block.BlockID) (ItemID, bool) {
+	for itemID, it := range Items {
+		if it.Placeable && it.Block == id {
+			return itemID, true
+		}
+	}
+	return ItemNone, false
+}
+
+// ItemStack is a count of identical items occupying one slot.
+type ItemStack struct {
+	ID       ItemID
+	Count    int
+	MaxStack int
+	Metadata map[string]string // Optional per-stack data (e.g. durability)
+}
+
+// NewStack creates a stack using the item's registered stack limit.
+func NewStack(id ItemID, count int) ItemStack {
+	maxStack := DefaultMaxStack
+	if it, ok := Items[id]; ok && it.MaxStack > 0 {
+		maxStack = it.MaxStack
+	}
+	return ItemStack{ID: id, Count: count, MaxStack: maxStack}
+}
+
+// IsEmpty reports whether the stack holds no items.
+func (s ItemStack) IsEmpty() bool {
+	return s.ID == ItemNone || s.Count <= 0
+}
+
+// Space returns how many more items fit onto the stack.
+func (s ItemStack) Space() int {
+	if s.IsEmpty() {
+		return 0
+	}
+	return s.MaxStack - s.Count
+}
+
+// CanStackWith reports whether two stacks can be merged into one slot.
+func (s ItemStack) CanStackWith(o ItemStack) bool {
+	if s.IsEmpty() || o.IsEmpty() || s.ID != o.ID {
+		return false
+	}
+	if len(s.Metadata) != len(o.Metadata) {
+		return false
+	}
+	for k, v := range s.Metadata {
+		if o.Metadata[k] != v {
+			return false
+		}
+	}
+	return true
+}
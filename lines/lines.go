@@ -0,0 +1,111 @@
+// Package lines collects world-space debug lines from any package and draws
+// them in one batch. Submitting lines needs no GL context; only Renderer
+// does, so headless code can call Line and Box freely.
+package lines
+
+import (
+	"something/metrics"
+	"something/shader"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Batch is a list of coloured line segments waiting to be drawn.
+type Batch struct {
+	vertices []float32 // x, y, z, r, g, b, a per vertex
+}
+
+// Line queues a segment from a to b.
+func (b *Batch) Line(from, to mgl32.Vec3, c mgl32.Vec4) {
+	b.vertices = append(b.vertices,
+		from[0], from[1], from[2], c[0], c[1], c[2], c[3],
+		to[0], to[1], to[2], c[0], c[1], c[2], c[3],
+	)
+}
+
+// Box queues the twelve edges of an axis-aligned box.
+func (b *Batch) Box(lo, hi mgl32.Vec3, c mgl32.Vec4) {
+	corner := func(i int) mgl32.Vec3 {
+		p := lo
+		for axis := 0; axis < 3; axis++ {
+			if i&(1<<axis) != 0 {
+				p[axis] = hi[axis]
+			}
+		}
+		return p
+	}
+	for i := 0; i < 8; i++ {
+		for axis := 0; axis < 3; axis++ {
+			if i&(1<<axis) == 0 {
+				b.Line(corner(i), corner(i|1<<axis), c)
+			}
+		}
+	}
+}
+
+// Len returns the number of queued segments.
+func (b *Batch) Len() int {
+	return len(b.vertices) / 14
+}
+
+// Reset drops every queued segment.
+func (b *Batch) Reset() {
+	b.vertices = b.vertices[:0]
+}
+
+// Default is the batch shared by the whole program.
+var Default = &Batch{}
+
+// Line queues a segment on the default batch.
+func Line(from, to mgl32.Vec3, c mgl32.Vec4) { Default.Line(from, to, c) }
+
+// Box queues a box on the default batch.
+func Box(lo, hi mgl32.Vec3, c mgl32.Vec4) { Default.Box(lo, hi, c) }
+
+// Renderer draws batches with the scene's view and projection.
+type Renderer struct {
+	program  *shader.Program
+	vao, vbo uint32
+}
+
+func NewRenderer() (*Renderer, error) {
+	program, err := shader.Load("lines")
+	if err != nil {
+		return nil, err
+	}
+	r := &Renderer{program: program}
+	gl.GenVertexArrays(1, &r.vao)
+	gl.GenBuffers(1, &r.vbo)
+	gl.BindVertexArray(r.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.vbo)
+	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 7*4, gl.PtrOffset(0))
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(1, 4, gl.FLOAT, false, 7*4, gl.PtrOffset(3*4))
+	gl.EnableVertexAttribArray(1)
+	gl.BindVertexArray(0)
+	return r, nil
+}
+
+// Draw renders and clears a batch. Lines are depth tested against the scene.
+func (r *Renderer) Draw(b *Batch, view, projection mgl32.Mat4) {
+	if len(b.vertices) == 0 {
+		return
+	}
+	viewProjection := projection.Mul4(view)
+	r.program.Use()
+	r.program.SetMat4("viewProjection", viewProjection)
+	gl.BindVertexArray(r.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, len(b.vertices)*4, gl.Ptr(b.vertices), gl.STREAM_DRAW)
+	gl.DrawArrays(gl.LINES, 0, int32(len(b.vertices)/7))
+	metrics.Add(metrics.DrawCalls, 1)
+	gl.BindVertexArray(0)
+	b.Reset()
+}
+
+func (r *Renderer) Cleanup() {
+	r.program.Delete()
+	gl.DeleteVertexArrays(1, &r.vao)
+	gl.DeleteBuffers(1, &r.vbo)
+}
@@ -1,12 +1,33 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt" // Added
 	"log"
+	"net"
+	"path/filepath"
 	"runtime"
+	"strings"
+	"time"
+
+	"something/achievements"
+	"something/admin"
+	"something/block"
+	"something/commands"
+	"something/console"
+	"something/crafting"
 	"something/debug"
 	"something/entities"
+	"something/events"
+	"something/item"
+	"something/lines"
+	"something/metrics"
 	"something/player"
+	"something/profile"
+	"something/shader"
+	"something/shadow"
+	"something/stats"
 	"something/world"
 
 	"github.com/go-gl/gl/v4.6-core/gl"
@@ -18,8 +39,79 @@ func init() {
 	runtime.LockOSThread()
 }
 
+var (
+	profileName = flag.String("profile", "player", "player profile used for saved progress")
+	worldName   = flag.String("world", "world", "name of the world save directory")
+	tracePath   = flag.String("trace", "", "record profiler scopes for the whole session to this Chrome trace file")
+	shadowFlag  = flag.String("shadows", string(shadow.Medium), "shadow quality: off, low, medium or high")
+	seedFlag    = flag.Int64("seed", world.DefaultSeed, "terrain seed")
+	adminAddr   = flag.String("admin", "", "serve the admin API on this address, e.g. "+admin.DefaultAddr)
+	adminToken  = flag.String("admin-token", "", "bearer token for the admin API; empty generates one")
+)
+
+// playerSaveInterval is how often the player's position is saved for the
+// web map, in seconds.
+const playerSaveInterval = 5
+
+// entitySaveInterval is how often loaded entities, stats and the set of
+// populated chunks are saved, in seconds.
+const entitySaveInterval = 30
+
+// deathHeight is the height below which the player dies and respawns.
+const deathHeight = -32
+
+// Camera projection; shadow cascades are fitted to the same frustum.
+const (
+	fovY      = 45 // Degrees
+	nearPlane = 0.1
+	farPlane  = 600.0 // Past the LOD distance so distant tiles are not clipped
+)
+
+// panoramaSize is the width of each cube face in a panorama capture.
+const panoramaSize = 1024
+
+// targetPad grows the target highlight so it does not z-fight the block.
+const targetPad = 0.005
+
+var (
+	colliderColor = mgl32.Vec4{1, 1, 1, 0.9}
+	targetColor   = mgl32.Vec4{0, 0, 0, 1}
+)
+
 func main() {
-	if err := run(); err != nil {
+	flag.Parse()
+	switch {
+	case *versionFlag:
+		fmt.Println(version)
+		return
+	case *rollbackFlag:
+		if err := rollback(); err != nil {
+			log.Fatalf("rollback failed: %v", err)
+		}
+		return
+	}
+	if restarted, err := checkStartup(); err != nil {
+		log.Printf("startup check failed: %v", err)
+	} else if restarted {
+		return
+	}
+	if *updateServer != "" {
+		// A failed update is not fatal; the current build still runs
+		if restarted, err := selfUpdate(); err != nil {
+			log.Printf("update failed: %v", err)
+		} else if restarted {
+			return
+		}
+	}
+	var err error
+	if *screenshotPath != "" {
+		if err = renderScreenshot(*screenshotPath); err == nil {
+			confirmStartup()
+		}
+	} else {
+		err = run()
+	}
+	if err != nil {
 		log.Fatalf("runtime error: %v", err)
 	}
 }
@@ -56,27 +148,191 @@ func run() error {
 	}
 	defer debugMenu.Cleanup()
 
+	worldDir := filepath.Join("saves", *worldName)
+	// A saved world keeps its seed; -seed only applies to new worlds
+	info := world.Info{Seed: *seedFlag}
+	if err := world.LoadInfo(worldDir, &info); err != nil {
+		return err
+	}
+	if err := world.SaveInfo(worldDir, info); err != nil {
+		return err
+	}
+
 	gameWorld := world.World{
-		Chunks:      make(map[[2]int]*world.Chunk),
-		ChunkRadius: 3,
+		Chunks:         make(map[[2]int]*world.Chunk),
+		ChunkRadius:    3,
+		ChunksPerFrame: 4,
+		Seed:           info.Seed,
+
+		LODDistance:      384,
+		LODTilesPerFrame: 8,
 	}
 	if err := gameWorld.Init(); err != nil {
 		return err
 	}
 	defer gameWorld.Cleanup()
-	initialChunk := world.NewChunk(0, 0)
+	gameWorld.SetTimeOfDay(world.TimesOfDay["day"])
+	initialChunk := world.NewChunk(0, 0, gameWorld.Seed)
 	initialChunk.UploadMesh()
 	gameWorld.Chunks[[2]int{0, 0}] = initialChunk
 
+	recipes, err := crafting.LoadRecipes("assets/recipes")
+	if err != nil {
+		return fmt.Errorf("failed to load recipes: %w", err)
+	}
+
+	bus := events.NewBus()
+	achievementDefs, err := achievements.LoadDefinitions("assets/achievements.json")
+	if err != nil {
+		return err
+	}
+	tracker := achievements.NewTracker(achievementDefs, bus)
+	profileDir := filepath.Join("profiles", *profileName)
+	if err := tracker.Load(filepath.Join(profileDir, "achievements.json")); err != nil {
+		return err
+	}
+	defer func() {
+		if err := tracker.Save(filepath.Join(profileDir, "achievements.json")); err != nil {
+			log.Printf("failed to save achievements: %v", err)
+		}
+	}()
+	bus.Subscribe(events.AchievementUnlocked, func(e events.Event) {
+		debugMenu.ShowToast("Achievement unlocked: " + tracker.Title(e.Subject))
+	})
+
+	playerStats := stats.New()
+	playerStats.Subscribe(bus)
+	statsPath := filepath.Join(worldDir, "stats", *profileName+".json")
+	if err := playerStats.Load(statsPath); err != nil {
+		return err
+	}
+	defer func() {
+		if err := playerStats.Save(statsPath); err != nil {
+			log.Printf("failed to save stats: %v", err)
+		}
+	}()
+	debugMenu.AddSection("Stats", playerStats.Lines)
+	models := entities.NewModelCache()
+	defer models.Cleanup()
+	entityManager := entities.NewManager(&gameWorld)
+	entityManager.SaveDir = filepath.Join(worldDir, "entities")
+	mobs, err := entities.RegisterMobTypes(entityManager, "assets/entities", models)
+	if err != nil {
+		return err
+	}
+	spawner := entities.NewSpawner(entityManager, &gameWorld, gameWorld.Seed)
+	spawner.AddMobs(mobs)
+	spawnerPath := filepath.Join(entityManager.SaveDir, "populated.json")
+	if err := spawner.Load(spawnerPath); err != nil {
+		return err
+	}
+	defer func() {
+		if err := spawner.Save(spawnerPath); err != nil {
+			log.Printf("failed to save spawner state: %v", err)
+		}
+	}()
+	debugMenu.AddSection("Spawner", spawner.Lines)
+	if err := entityManager.Open(); err != nil {
+		return err
+	}
+	defer func() {
+		if err := entityManager.Close(); err != nil {
+			log.Printf("failed to save entities: %v", err)
+		}
+	}()
+	gameWorld.OnChunkGenerated = func(x, z int, c *world.Chunk) {
+		// Chunks are regenerated whenever they reload; count only the first
+		if !spawner.Populated(x, z) {
+			bus.Publish(events.Event{Type: events.ChunkGenerated, Amount: 1})
+		}
+		if err := entityManager.LoadChunk(x, z); err != nil {
+			log.Printf("failed to load entities for chunk %d,%d: %v", x, z, err)
+		}
+		if _, err := spawner.PopulateChunk(x, z); err != nil {
+			log.Printf("failed to populate chunk %d,%d: %v", x, z, err)
+		}
+	}
+	gameWorld.OnChunkUnloaded = func(x, z int, c *world.Chunk) {
+		if err := entityManager.UnloadChunk(x, z); err != nil {
+			log.Printf("failed to save entities for chunk %d,%d: %v", x, z, err)
+		}
+	}
+	if err := entityManager.LoadChunk(0, 0); err != nil {
+		return err
+	}
+	if _, err := spawner.PopulateChunk(0, 0); err != nil {
+		return err
+	}
+
 	// Temporary fixed spawn (remove once GetSurfaceHeight is verified)
-	player := player.NewPlayer(mgl32.Vec3{0, 10, 0})
-	pony, err := entities.NewPony(mgl32.Vec3{0, 10, 0}, mgl32.Vec3{0, 0, 0})
+	spawn := mgl32.Vec3{0, 10, 0}
+	player := player.NewPlayer(spawn)
+	entityManager.PlayerBody = &player.Body
+	playerPath := filepath.Join(worldDir, "players", *profileName+".json")
+	defer func() {
+		if err := player.SaveState(playerPath); err != nil {
+			log.Printf("failed to save player: %v", err)
+		}
+	}()
+
+	shadowQuality, err := shadow.ParseQuality(*shadowFlag)
+	if err != nil {
+		return err
+	}
+	shadows, err := shadow.New(shadowQuality)
+	if err != nil {
+		log.Printf("shadows disabled: %v", err)
+		if shadows, err = shadow.New(shadow.Off); err != nil {
+			return err
+		}
+	}
+	defer shadows.Cleanup()
+	gameWorld.Shadows = shadows
+
+	game := &commands.Game{
+		World:         &gameWorld,
+		Entities:      entityManager,
+		Player:        &player.Body,
+		Shadows:       shadows,
+		LookDir:       func() mgl32.Vec3 { return player.Camera.Front },
+		ReloadShaders: shader.ReloadAll,
+	}
+	registry := console.NewRegistry()
+	if err := commands.Register(registry, game); err != nil {
+		return err
+	}
+	var adminServer *admin.Server
+	if *adminAddr != "" {
+		var addr net.Addr
+		if adminServer, addr, err = admin.Start(*adminAddr, *adminToken, game, playerStats); err != nil {
+			return err
+		}
+		defer adminServer.Close()
+		log.Printf("admin API on http://%s (token %s)", addr, adminServer.Token)
+	}
+	devConsole := console.New(registry)
+	debugMenu.Console = devConsole
+
+	lineRenderer, err := lines.NewRenderer()
 	if err != nil {
 		return err
 	}
+	defer lineRenderer.Cleanup()
+	var showChunkBorders, showColliders, showTarget bool
+	debugMenu.AddView(glfw.KeyF4, "Chunk borders", &showChunkBorders)
+	debugMenu.AddView(glfw.KeyF5, "Wireframe", &gameWorld.Wireframe)
+	debugMenu.AddView(glfw.KeyF6, "Collision boxes", &showColliders)
+	debugMenu.AddView(glfw.KeyF7, "Target block", &showTarget)
+	debugMenu.AddView(glfw.KeyF8, "Normals", &gameWorld.ShowNormals)
+	debugMenu.AddView(glfw.KeyF9, "Mesh age", &gameWorld.MeshAgeTint)
+	debugMenu.AddView(glfw.KeyF10, "Shadow cascades", &shadows.ShowCascades)
 
 	width, height := window.GetSize()
-	projection := mgl32.Perspective(mgl32.DegToRad(45), float32(width)/float32(height), 0.1, 100.0)
+	aspect := float32(width) / float32(height)
+	projection := mgl32.Perspective(mgl32.DegToRad(fovY), aspect, nearPlane, farPlane)
+	gameScene := &scene{world: &gameWorld, shadows: shadows, entities: entityManager}
+	// Captures are taken once the frame is drawn, before it is swapped
+	var screenshotRequested, panoramaRequested bool
 
 	cursorCaptured := true
 	window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
@@ -97,7 +353,38 @@ func run() error {
 		player.Camera.ProcessMouse(xoffset, yoffset)
 	})
 
+	window.SetCharCallback(func(w *glfw.Window, char rune) {
+		if devConsole.Open && char != '`' {
+			devConsole.Type(char)
+		}
+	})
+
 	window.SetKeyCallback(func(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+		if key == glfw.KeyGraveAccent && action == glfw.Press {
+			devConsole.Toggle()
+			return
+		}
+		if devConsole.Open {
+			// The console has keyboard focus until it is closed
+			if action == glfw.Release {
+				return
+			}
+			switch key {
+			case glfw.KeyEnter:
+				devConsole.Submit()
+			case glfw.KeyBackspace:
+				devConsole.Backspace()
+			case glfw.KeyUp:
+				devConsole.HistoryPrev()
+			case glfw.KeyDown:
+				devConsole.HistoryNext()
+			case glfw.KeyTab:
+				devConsole.Complete()
+			case glfw.KeyEscape:
+				devConsole.Toggle()
+			}
+			return
+		}
 		if key == glfw.KeyEscape && action == glfw.Press {
 			cursorCaptured = !cursorCaptured
 			if cursorCaptured {
@@ -110,32 +397,214 @@ func run() error {
 		if key == glfw.KeyF1 && action == glfw.Press {
 			debugMenu.Toggle()
 		}
+		if action == glfw.Press && debugMenu.HandleKey(key) {
+			return
+		}
+		if key == glfw.KeyF2 && action == glfw.Press {
+			// Shift+F2 captures a panorama instead of the screen
+			if mods&glfw.ModShift != 0 {
+				panoramaRequested = true
+			} else {
+				screenshotRequested = true
+			}
+		}
+		if key == glfw.KeyF3 && action == glfw.Press {
+			// Toggle trace recording; stopping saves what was recorded. With
+			// -trace the whole session is already recording, and stopping
+			// would cut short the trace saved at exit.
+			if *tracePath != "" {
+				debugMenu.ShowToast("Recording the session to " + *tracePath)
+			} else if !profile.Default.Tracing() {
+				profile.Default.StartTrace()
+				debugMenu.ShowToast("Recording trace")
+			} else {
+				profile.Default.StopTrace()
+				path := filepath.Join("traces", time.Now().Format("20060102-150405")+".json")
+				if err := profile.Default.SaveTrace(path); err != nil {
+					log.Printf("failed to save trace: %v", err)
+				} else {
+					debugMenu.ShowToast("Trace saved to " + path)
+				}
+			}
+		}
 		if key == glfw.KeyQ && action == glfw.Press {
 			w.SetShouldClose(true)
 		}
+		if key == glfw.KeyC && action == glfw.Press {
+			// Craft the first recipe the inventory has ingredients for
+			if craftable := recipes.Craftable(player.Inventory); len(craftable) > 0 {
+				if crafted, err := crafting.CraftFromInventory(player.Inventory, craftable[0]); err != nil {
+					log.Printf("craft failed: %v", err)
+					if errors.Is(err, crafting.ErrNoRoom) {
+						debugMenu.ShowToast("Inventory full")
+					}
+				} else {
+					bus.Publish(events.Event{Type: events.ItemCrafted, Subject: item.Items[crafted.ID].Name, Amount: float64(crafted.Count)})
+				}
+			}
+		}
+		if key >= glfw.Key1 && key <= glfw.Key9 && action == glfw.Press {
+			player.Inventory.Select(int(key - glfw.Key1))
+		}
+	})
+
+	window.SetScrollCallback(func(w *glfw.Window, xoff, yoff float64) {
+		if devConsole.Open {
+			return
+		}
+		if yoff > 0 {
+			player.Inventory.Scroll(-1)
+		} else if yoff < 0 {
+			player.Inventory.Scroll(1)
+		}
+	})
+
+	window.SetMouseButtonCallback(func(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+		if !cursorCaptured || devConsole.Open || action != glfw.Press {
+			return
+		}
+		switch button {
+		case glfw.MouseButtonLeft:
+			if id, _, ok := entityManager.RayHit(player.Camera.Position, player.Camera.Front, player.Reach); ok {
+				entityManager.Startle(id, player.Position)
+				return
+			}
+			if id, ok := player.BreakBlock(&gameWorld); ok {
+				bus.Publish(events.Event{Type: events.BlockBroken, Subject: block.Names[id]})
+			}
+		case glfw.MouseButtonRight:
+			if id, _, ok := entityManager.RayHit(player.Camera.Position, player.Camera.Front, player.Reach); ok {
+				if entityManager.Types[id] == entities.PonyType {
+					bus.Publish(events.Event{Type: events.PonyInteracted, Subject: entities.PonyType})
+				}
+				return
+			}
+			if id, ok := player.PlaceBlock(&gameWorld); ok {
+				bus.Publish(events.Event{Type: events.BlockPlaced, Subject: block.Names[id]})
+			}
+		}
 	})
 
+	if *tracePath != "" {
+		profile.Default.StartTrace()
+		defer func() {
+			if err := profile.Default.SaveTrace(*tracePath); err != nil {
+				log.Printf("failed to save trace: %v", err)
+			}
+		}()
+	}
+
 	lastTime := glfw.GetTime()
+	var sincePlayerSave, sinceEntitySave float32
+	startupConfirmed := false
 	for !window.ShouldClose() {
+		endFrame := profile.Begin("frame")
 		currentTime := glfw.GetTime()
 		deltaTime := float32(currentTime - lastTime)
 		lastTime = currentTime
 
+		player.IgnoreInput = devConsole.Open
+		lastPos, wasOnGround := player.Position, player.OnGround
 		player.Update(window, &gameWorld, deltaTime)
+		delta := player.Position.Sub(lastPos)
+		if moved := delta.Len(); moved > 0 {
+			bus.Publish(events.Event{Type: events.DistanceTravelled, Amount: float64(moved)})
+		}
+		if wasOnGround && !player.OnGround && player.Velocity.Y() > 0 {
+			bus.Publish(events.Event{Type: events.PlayerJumped, Amount: 1})
+		}
+		playerStats.RecordMovement(delta.X(), delta.Y(), delta.Z(), player.OnGround)
+		playerStats.AddPlayTime(deltaTime)
+		if sincePlayerSave += deltaTime; sincePlayerSave >= playerSaveInterval {
+			sincePlayerSave = 0
+			if err := player.SaveState(playerPath); err != nil {
+				log.Printf("failed to save player: %v", err)
+			}
+		}
+		if sinceEntitySave += deltaTime; sinceEntitySave >= entitySaveInterval {
+			sinceEntitySave = 0
+			if err := entityManager.Save(); err != nil {
+				log.Printf("failed to save entities: %v", err)
+			}
+			if err := playerStats.Save(statsPath); err != nil {
+				log.Printf("failed to save stats: %v", err)
+			}
+			if err := spawner.Save(spawnerPath); err != nil {
+				log.Printf("failed to save spawner state: %v", err)
+			}
+		}
+		if player.Position.Y() < deathHeight {
+			player.Respawn(spawn)
+			bus.Publish(events.Event{Type: events.PlayerDied, Amount: 1})
+		}
+		entityManager.Player = entities.PlayerState{
+			Position: player.Position,
+			Holding:  item.Items[player.Inventory.SelectedStack().ID].Name,
+		}
+		if err := entityManager.Update(deltaTime); err != nil {
+			log.Printf("entity update failed: %v", err)
+		}
+		spawner.Update(deltaTime)
 		debugMenu.Update(deltaTime)
+		gameWorld.Tick(deltaTime)
+		if adminServer != nil {
+			adminServer.Process()
+		}
+		if reloaded, err := shader.Poll(); err != nil {
+			log.Printf("shader reload failed:\n%v", err)
+			debugMenu.ShowToast("Shader error; see log")
+		} else if len(reloaded) > 0 {
+			debugMenu.ShowToast("Reloaded shaders: " + strings.Join(reloaded, ", "))
+		}
 		gameWorld.UpdateChunks(player.Camera.Position)
 
-		gl.ClearColor(0.2, 0.3, 0.3, 1.0)
 		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
 		view := player.Camera.GetViewMatrix()
-		gameWorld.Render(view, projection, player.Camera.Position)
-		pony.Render(view, projection)
+		gameScene.draw(player.Camera.Position, view, mgl32.DegToRad(fovY), aspect)
+		if showChunkBorders {
+			gameWorld.DrawChunkBorders(player.Position)
+		}
+		if showColliders {
+			boxMin, boxMax := player.Bounds(player.Position)
+			lines.Box(boxMin, boxMax, colliderColor)
+			entityManager.DrawColliders(colliderColor)
+		}
+		if showTarget && player.HasTarget {
+			t := mgl32.Vec3{float32(player.Target[0]), float32(player.Target[1]), float32(player.Target[2])}
+			pad := mgl32.Vec3{targetPad, targetPad, targetPad}
+			lines.Box(t.Sub(pad), t.Add(mgl32.Vec3{1, 1, 1}).Add(pad), targetColor)
+		}
+		lineRenderer.Draw(lines.Default, view, projection)
 		gl.BindVertexArray(0)
-		debugMenu.Render(player.Position)
+		debugMenu.Render()
+		if screenshotRequested {
+			screenshotRequested = false
+			if path, err := saveScreenshot(window); err != nil {
+				log.Printf("failed to save screenshot: %v", err)
+			} else {
+				debugMenu.ShowToast("Screenshot saved to " + path)
+			}
+		}
+		if panoramaRequested {
+			panoramaRequested = false
+			if dir, err := savePanorama(gameScene, player.Camera.Position, panoramaSize); err != nil {
+				log.Printf("failed to save panorama: %v", err)
+			} else {
+				debugMenu.ShowToast("Panorama saved to " + dir)
+			}
+		}
 
 		window.SwapBuffers()
+		if !startupConfirmed {
+			// The first frame is on screen, so this build starts fine
+			confirmStartup()
+			startupConfirmed = true
+		}
 		glfw.PollEvents()
+		endFrame()
+		profile.EndFrame()
+		metrics.EndFrame()
 	}
 	return nil
 }
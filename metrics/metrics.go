@@ -0,0 +1,216 @@
+// Package metrics is a registry of named values that subsystems publish and
+// overlays read, so readouts do not have to be passed through function
+// arguments.
+package metrics
+
+import (
+	"sort"
+	"sync"
+)
+
+// windowSize is how many samples Observe keeps per series.
+const windowSize = 240
+
+// Names of the metrics published by the game.
+const (
+	DrawCalls    = "render.draw_calls" // Counter
+	FrameTime    = "frame.ms"          // Series
+	Chunks       = "world.chunks"      // Loaded chunks
+	Vertices     = "world.vertices"    // Vertices in loaded chunk meshes
+	ChunkQueue   = "world.chunk_queue" // Chunks waiting to be generated
+	LODTiles     = "world.lod_tiles"   // Uploaded far-terrain tiles
+	Entities     = "entities.count"    // Loaded entities
+	PlayerPos    = "player.position"   // Text
+	PlayerChunk  = "player.chunk"      // Text: chunk and local coordinates
+	PlayerFacing = "player.facing"     // Text
+	PlayerTarget = "player.target"     // Text: targeted block
+	PlayerBiome  = "player.biome"      // Text
+	WorldTime    = "world.time"        // Text: day and time of day
+	HeapAlloc    = "go.heap_alloc"     // Bytes
+	HeapSys      = "go.heap_sys"       // Bytes
+	NumGC        = "go.num_gc"         // Completed GC cycles
+	GCPause      = "go.gc_pause_ms"    // Most recent GC pause
+	Goroutines   = "go.goroutines"
+)
+
+// Registry holds gauges, text values, per-frame counters and sample series.
+type Registry struct {
+	mu      sync.Mutex
+	gauges  map[string]float64
+	texts   map[string]string
+	counts  map[string]float64 // Accumulating during the current frame
+	frame   map[string]float64 // Counter totals of the last finished frame
+	samples map[string]*series
+}
+
+// Summary describes the samples of a series.
+type Summary struct {
+	Min, Avg, Max, Last float64
+	Count               int
+}
+
+type series struct {
+	values []float64
+	next   int
+}
+
+// Default is the registry used by the package-level functions.
+var Default = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{
+		gauges:  make(map[string]float64),
+		texts:   make(map[string]string),
+		counts:  make(map[string]float64),
+		frame:   make(map[string]float64),
+		samples: make(map[string]*series),
+	}
+}
+
+// Set stores the current value of a gauge.
+func (r *Registry) Set(name string, v float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gauges[name] = v
+}
+
+// SetText stores a value that is better shown as text, such as a biome.
+func (r *Registry) SetText(name, v string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.texts[name] = v
+}
+
+// Add increments a per-frame counter, such as draw calls. Get returns the
+// total of the last frame finished with EndFrame.
+func (r *Registry) Add(name string, delta float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counts[name] += delta
+}
+
+// EndFrame publishes the per-frame counters and starts counting from zero.
+func (r *Registry) EndFrame() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name := range r.frame {
+		r.frame[name] = 0
+	}
+	for name, v := range r.counts {
+		r.frame[name] = v
+		delete(r.counts, name)
+	}
+}
+
+// Observe appends a sample to a series, keeping the last windowSize samples.
+func (r *Registry) Observe(name string, v float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.samples[name]
+	if !ok {
+		s = &series{}
+		r.samples[name] = s
+	}
+	if len(s.values) < windowSize {
+		s.values = append(s.values, v)
+	} else {
+		s.values[s.next] = v
+	}
+	s.next = (s.next + 1) % windowSize
+}
+
+// Get returns a gauge or the last frame's counter total.
+func (r *Registry) Get(name string) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, ok := r.gauges[name]; ok {
+		return v
+	}
+	return r.frame[name]
+}
+
+// Text returns a text value, or "" if it was never set.
+func (r *Registry) Text(name string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.texts[name]
+}
+
+// Summary returns the min, average, max and latest sample of a series.
+func (r *Registry) Summary(name string) Summary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.samples[name]
+	if !ok || len(s.values) == 0 {
+		return Summary{}
+	}
+	sum := Summary{Min: s.values[0], Max: s.values[0], Count: len(s.values)}
+	total := 0.0
+	for _, v := range s.values {
+		sum.Min = min(sum.Min, v)
+		sum.Max = max(sum.Max, v)
+		total += v
+	}
+	sum.Avg = total / float64(len(s.values))
+	sum.Last = s.values[(s.next+len(s.values)-1)%len(s.values)]
+	return sum
+}
+
+// Samples returns a series oldest first.
+func (r *Registry) Samples(name string) []float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.samples[name]
+	if !ok {
+		return nil
+	}
+	if len(s.values) < windowSize {
+		return append([]float64(nil), s.values...)
+	}
+	return append(append([]float64(nil), s.values[s.next:]...), s.values[:s.next]...)
+}
+
+// Names returns every registered name, sorted.
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	seen := make(map[string]bool)
+	for _, m := range []map[string]float64{r.gauges, r.counts, r.frame} {
+		for name := range m {
+			seen[name] = true
+		}
+	}
+	for name := range r.texts {
+		seen[name] = true
+	}
+	for name := range r.samples {
+		seen[name] = true
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Set stores a gauge in the default registry.
+func Set(name string, v float64) { Default.Set(name, v) }
+
+// SetText stores a text value in the default registry.
+func SetText(name, v string) { Default.SetText(name, v) }
+
+// Add increments a per-frame counter in the default registry.
+func Add(name string, delta float64) { Default.Add(name, delta) }
+
+// EndFrame finishes the default registry's frame.
+func EndFrame() { Default.EndFrame() }
+
+// Observe appends a sample to a series in the default registry.
+func Observe(name string, v float64) { Default.Observe(name, v) }
+
+// Get reads a gauge or counter from the default registry.
+func Get(name string) float64 { return Default.Get(name) }
+
+// Text reads a text value from the default registry.
+func Text(name string) string { return Default.Text(name) }
@@ -0,0 +1,177 @@
+package pathfind
+
+import (
+	"container/heap"
+	"math"
+)
+
+// Terrain answers voxel solidity queries; *world.World implements it.
+type Terrain interface {
+	IsSolid(x, y, z int) bool
+}
+
+// Pos is a block position. For path nodes it is the block the agent's feet
+// occupy.
+type Pos [3]int
+
+// Agent describes the body that has to fit along the path.
+type Agent struct {
+	Width      float32 // Footprint width in blocks
+	Height     float32 // Body height in blocks
+	StepHeight int     // Highest ledge the agent can climb in one move
+	MaxDrop    int     // Furthest the agent is willing to fall in one move
+}
+
+// DefaultMaxNodes bounds the search when FindPath is given a limit of 0.
+const DefaultMaxNodes = 4000
+
+const (
+	stepUpCost = 0.5 // Extra cost for climbing a block
+	dropCost   = 0.2 // Extra cost per block dropped
+)
+
+// radius returns how many cells the footprint extends from its center cell.
+func (a Agent) radius() int {
+	return int(math.Ceil(float64(a.Width-1) / 2))
+}
+
+func (a Agent) height() int {
+	return int(math.Ceil(float64(a.Height)))
+}
+
+// fits reports whether the agent's body fits with its feet in pos.
+func (a Agent) fits(t Terrain, pos Pos) bool {
+	r, h := a.radius(), a.height()
+	for dx := -r; dx <= r; dx++ {
+		for dz := -r; dz <= r; dz++ {
+			for dy := 0; dy < h; dy++ {
+				if t.IsSolid(pos[0]+dx, pos[1]+dy, pos[2]+dz) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// CanStand reports whether the agent fits at pos with solid ground below.
+func (a Agent) CanStand(t Terrain, pos Pos) bool {
+	return t.IsSolid(pos[0], pos[1]-1, pos[2]) && a.fits(t, pos)
+}
+
+// neighbors returns the positions reachable in one move from pos and their cost.
+func (a Agent) neighbors(t Terrain, pos Pos) ([]Pos, []float64) {
+	var out []Pos
+	var costs []float64
+	for _, d := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+		nx, nz := pos[0]+d[0], pos[2]+d[1]
+		// Climb: the body must clear the ledge above the current position
+		for up := 1; up <= a.StepHeight; up++ {
+			if !a.fits(t, Pos{pos[0], pos[1] + up, pos[2]}) {
+				break
+			}
+			if n := (Pos{nx, pos[1] + up, nz}); a.CanStand(t, n) {
+				out = append(out, n)
+				costs = append(costs, 1+stepUpCost*float64(up))
+				break
+			}
+		}
+		// Walk or drop: the column in front must be clear down to the landing
+		for down := 0; down <= a.MaxDrop; down++ {
+			n := Pos{nx, pos[1] - down, nz}
+			if !a.fits(t, n) {
+				break
+			}
+			if t.IsSolid(n[0], n[1]-1, n[2]) {
+				out = append(out, n)
+				costs = append(costs, 1+dropCost*float64(down))
+				break
+			}
+		}
+	}
+	return out, costs
+}
+
+// FindPath runs A* from start to goal and returns the positions to visit,
+// excluding start. It gives up after expanding maxNodes nodes.
+func FindPath(t Terrain, a Agent, start, goal Pos, maxNodes int) ([]Pos, bool) {
+	if maxNodes <= 0 {
+		maxNodes = DefaultMaxNodes
+	}
+	if !a.CanStand(t, goal) {
+		return nil, false
+	}
+	open := &nodeHeap{}
+	heap.Push(open, &node{pos: start, f: heuristic(start, goal)})
+	cameFrom := make(map[Pos]Pos)
+	gScore := map[Pos]float64{start: 0}
+	closed := make(map[Pos]bool)
+	for expanded := 0; open.Len() > 0 && expanded < maxNodes; expanded++ {
+		cur := heap.Pop(open).(*node)
+		if cur.pos == goal {
+			return reconstruct(cameFrom, start, goal), true
+		}
+		if closed[cur.pos] {
+			continue
+		}
+		closed[cur.pos] = true
+		next, costs := a.neighbors(t, cur.pos)
+		for i, n := range next {
+			g := gScore[cur.pos] + costs[i]
+			if old, ok := gScore[n]; ok && g >= old {
+				continue
+			}
+			gScore[n] = g
+			cameFrom[n] = cur.pos
+			heap.Push(open, &node{pos: n, g: g, f: g + heuristic(n, goal)})
+		}
+	}
+	return nil, false
+}
+
+// heuristic is the horizontal Manhattan distance; every move costs at least 1.
+func heuristic(a, b Pos) float64 {
+	return math.Abs(float64(a[0]-b[0])) + math.Abs(float64(a[2]-b[2]))
+}
+
+func reconstruct(cameFrom map[Pos]Pos, start, goal Pos) []Pos {
+	var path []Pos
+	for cur := goal; cur != start; cur = cameFrom[cur] {
+		path = append(path, cur)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// FindGround returns the nearest standable position in the column at
+// (x, z), searching up to dy blocks above and below y.
+func FindGround(t Terrain, a Agent, x, y, z, dy int) (Pos, bool) {
+	for i := 0; i <= dy; i++ {
+		for _, yy := range []int{y - i, y + i} {
+			if p := (Pos{x, yy, z}); a.CanStand(t, p) {
+				return p, true
+			}
+		}
+	}
+	return Pos{}, false
+}
+
+type node struct {
+	pos  Pos
+	g, f float64
+}
+
+type nodeHeap []*node
+
+func (h nodeHeap) Len() int           { return len(h) }
+func (h nodeHeap) Less(i, j int) bool { return h[i].f < h[j].f }
+func (h nodeHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *nodeHeap) Push(x any)        { *h = append(*h, x.(*node)) }
+func (h *nodeHeap) Pop() any {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
@@ -0,0 +1,146 @@
+package pathfind
+
+import "testing"
+
+// blocks is a map-backed Terrain.
+type blocks map[Pos]bool
+
+func (b blocks) IsSolid(x, y, z int) bool { return b[Pos{x, y, z}] }
+
+// box fills every block between two corners, inclusive.
+func (b blocks) box(x0, y0, z0, x1, y1, z1 int) blocks {
+	for x := x0; x <= x1; x++ {
+		for y := y0; y <= y1; y++ {
+			for z := z0; z <= z1; z++ {
+				b[Pos{x, y, z}] = true
+			}
+		}
+	}
+	return b
+}
+
+var walker = Agent{Width: 1, Height: 2, StepHeight: 1, MaxDrop: 3}
+
+func TestStepHeight(t *testing.T) {
+	tests := []struct {
+		name   string
+		ledge  int // Height of the raised floor
+		step   int
+		wantOK bool
+	}{
+		{"one block step", 1, 1, true},
+		{"two block step refused", 2, 1, false},
+		{"two block step with higher step height", 2, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A one-wide corridor with floor at y=0 that rises at x=3
+			terrain := blocks{}.box(0, 0, 0, 5, 0, 0).box(3, 1, 0, 5, tt.ledge, 0)
+			a := walker
+			a.StepHeight = tt.step
+			goal := Pos{5, tt.ledge + 1, 0}
+			path, ok := FindPath(terrain, a, Pos{0, 1, 0}, goal, 0)
+			if ok != tt.wantOK {
+				t.Fatalf("FindPath found = %v, want %v (path %v)", ok, tt.wantOK, path)
+			}
+			if ok && path[len(path)-1] != goal {
+				t.Errorf("path ends at %v, want %v", path[len(path)-1], goal)
+			}
+		})
+	}
+}
+
+func TestDropLimit(t *testing.T) {
+	// A platform with feet at y=4 next to a floor with feet at y=1
+	terrain := blocks{}.box(0, 0, 0, 2, 3, 0).box(3, 0, 0, 5, 0, 0)
+	for _, tt := range []struct {
+		maxDrop int
+		wantOK  bool
+	}{
+		{2, false},
+		{3, true},
+	} {
+		a := walker
+		a.MaxDrop = tt.maxDrop
+		if _, ok := FindPath(terrain, a, Pos{0, 4, 0}, Pos{5, 1, 0}, 0); ok != tt.wantOK {
+			t.Errorf("MaxDrop %d: found = %v, want %v", tt.maxDrop, ok, tt.wantOK)
+		}
+	}
+	// Climbing back up three blocks is never possible
+	if _, ok := FindPath(terrain, walker, Pos{5, 1, 0}, Pos{0, 4, 0}, 0); ok {
+		t.Error("found a path up a three block cliff")
+	}
+}
+
+func TestClearance(t *testing.T) {
+	// A 5-wide floor split at x=3 by a wall with a one-wide gap at z=0
+	gap := blocks{}.box(0, 0, -2, 6, 0, 2).box(3, 1, -2, 3, 3, 2)
+	delete(gap, Pos{3, 1, 0})
+	delete(gap, Pos{3, 2, 0})
+	delete(gap, Pos{3, 3, 0})
+	// A one-wide corridor with a ceiling block at head height over x=3
+	ceiling := blocks{}.box(0, 0, 0, 6, 0, 0).box(3, 2, 0, 3, 2, 0)
+
+	tests := []struct {
+		name    string
+		terrain blocks
+		agent   Agent
+		start   Pos
+		wantOK  bool
+	}{
+		{"narrow agent through gap", gap, walker, Pos{0, 1, 0}, true},
+		{"wide agent refused by gap", gap, Agent{Width: 2, Height: 2, StepHeight: 1}, Pos{1, 1, 0}, false},
+		{"short agent under ceiling", ceiling, Agent{Width: 1, Height: 1, StepHeight: 1}, Pos{0, 1, 0}, true},
+		{"tall agent refused by ceiling", ceiling, walker, Pos{0, 1, 0}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goal := Pos{5, 1, 0}
+			if !tt.agent.CanStand(tt.terrain, tt.start) || !tt.agent.CanStand(tt.terrain, goal) {
+				t.Fatal("start or goal is not standable")
+			}
+			path, ok := FindPath(tt.terrain, tt.agent, tt.start, goal, 0)
+			if ok != tt.wantOK {
+				t.Fatalf("found = %v, want %v (path %v)", ok, tt.wantOK, path)
+			}
+			for _, p := range path {
+				if !tt.agent.fits(tt.terrain, p) {
+					t.Errorf("path passes through %v where the agent does not fit", p)
+				}
+			}
+		})
+	}
+}
+
+func TestUnreachable(t *testing.T) {
+	// Two platforms separated by a gap too wide and deep to cross
+	terrain := blocks{}.box(0, 0, 0, 3, 0, 0).box(8, 0, 0, 10, 0, 0)
+	if _, ok := FindPath(terrain, walker, Pos{0, 1, 0}, Pos{10, 1, 0}, 0); ok {
+		t.Error("found a path to an isolated platform")
+	}
+	if _, ok := FindPath(terrain, walker, Pos{0, 1, 0}, Pos{5, 1, 0}, 0); ok {
+		t.Error("found a path to a goal with no ground")
+	}
+}
+
+func TestMaxNodes(t *testing.T) {
+	terrain := blocks{}.box(0, 0, 0, 100, 0, 0)
+	start, goal := Pos{0, 1, 0}, Pos{100, 1, 0}
+	if _, ok := FindPath(terrain, walker, start, goal, 10); ok {
+		t.Error("found a 100 block path expanding only 10 nodes")
+	}
+	path, ok := FindPath(terrain, walker, start, goal, 0)
+	if !ok || len(path) != 100 {
+		t.Errorf("default limit: found = %v with %d steps, want 100", ok, len(path))
+	}
+}
+
+func TestFindGround(t *testing.T) {
+	terrain := blocks{}.box(0, 0, 0, 0, 4, 0)
+	if p, ok := FindGround(terrain, walker, 0, 2, 0, 5); !ok || p != (Pos{0, 5, 0}) {
+		t.Errorf("FindGround = %v, %v; want {0 5 0}", p, ok)
+	}
+	if _, ok := FindGround(terrain, walker, 0, 12, 0, 5); ok {
+		t.Error("FindGround found ground out of range")
+	}
+}
@@ -0,0 +1,127 @@
+package physics
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Gravity is the downward acceleration applied to bodies, in blocks/s².
+const Gravity = -25.0
+
+// Terrain answers voxel solidity queries; *world.World implements it.
+type Terrain interface {
+	IsSolid(x, y, z int) bool
+}
+
+// Body is an axis-aligned box. Position is the bottom-center of the box.
+type Body struct {
+	Position mgl32.Vec3
+	Velocity mgl32.Vec3
+	Width    float32
+	Height   float32
+	OnGround bool
+}
+
+// Bounds returns the box's minimum and maximum corners at pos.
+func (b *Body) Bounds(pos mgl32.Vec3) (boxMin, boxMax mgl32.Vec3) {
+	boxMin = mgl32.Vec3{pos.X() - b.Width/2, pos.Y(), pos.Z() - b.Width/2}
+	boxMax = mgl32.Vec3{pos.X() + b.Width/2, pos.Y() + b.Height, pos.Z() + b.Width/2}
+	return boxMin, boxMax
+}
+
+// Collides reports whether the box at pos overlaps a solid voxel.
+func (b *Body) Collides(t Terrain, pos mgl32.Vec3) bool {
+	boxMin, boxMax := b.Bounds(pos)
+	for x := floor(boxMin.X()); x <= floor(boxMax.X()); x++ {
+		for y := floor(boxMin.Y()); y <= floor(boxMax.Y()); y++ {
+			for z := floor(boxMin.Z()); z <= floor(boxMax.Z()); z++ {
+				if t.IsSolid(x, y, z) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Step applies gravity and moves the body by its velocity.
+func (b *Body) Step(t Terrain, dt float32) {
+	b.Velocity[1] += Gravity * dt
+	b.Move(t, dt)
+}
+
+// Move advances the body one axis at a time, stopping at solid voxels.
+// Hitting the ground sets OnGround; any blocked axis loses its velocity.
+func (b *Body) Move(t Terrain, dt float32) {
+	b.OnGround = false
+	for axis := 0; axis < 3; axis++ {
+		test := b.Position
+		test[axis] += b.Velocity[axis] * dt
+		if !b.Collides(t, test) {
+			b.Position = test
+			continue
+		}
+		if axis == 1 && b.Velocity[1] < 0 {
+			b.OnGround = true
+		}
+		b.Velocity[axis] = 0
+	}
+}
+
+// Overlaps reports whether two bodies intersect.
+func Overlaps(a, b *Body) bool {
+	aMin, aMax := a.Bounds(a.Position)
+	bMin, bMax := b.Bounds(b.Position)
+	for i := 0; i < 3; i++ {
+		if aMax[i] <= bMin[i] || bMax[i] <= aMin[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Push separates two overlapping bodies horizontally, each moving half of
+// strength times the overlap. Moves that would enter terrain are skipped.
+func Push(t Terrain, a, b *Body, strength float32) {
+	if !Overlaps(a, b) {
+		return
+	}
+	d := b.Position.Sub(a.Position)
+	d[1] = 0
+	dist := d.Len()
+	if dist < 1e-4 {
+		// Coincident bodies: pick a direction, the overlap is the full width
+		d, dist = mgl32.Vec3{1, 0, 0}, 0
+	}
+	overlap := (a.Width+b.Width)/2 - dist
+	if overlap <= 0 {
+		return
+	}
+	shift := d.Normalize().Mul(overlap * strength / 2)
+	if next := a.Position.Sub(shift); !a.Collides(t, next) {
+		a.Position = next
+	}
+	if next := b.Position.Add(shift); !b.Collides(t, next) {
+		b.Position = next
+	}
+}
+
+// Simulate steps every body for the given number of fixed ticks, pushing
+// overlapping bodies apart after each tick.
+func Simulate(t Terrain, bodies []*Body, dt float32, ticks int) {
+	for i := 0; i < ticks; i++ {
+		for _, b := range bodies {
+			b.Step(t, dt)
+		}
+		for j := range bodies {
+			for k := j + 1; k < len(bodies); k++ {
+				Push(t, bodies[j], bodies[k], 1)
+			}
+		}
+	}
+}
+
+func floor(v float32) int {
+	return int(math.Floor(float64(v)))
+}
@@ -0,0 +1,104 @@
+package physics
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const tick = float32(1) / 60
+
+func TestFallAndLand(t *testing.T) {
+	terrain := NewVoxelMap(1) // Floor top at y=1
+	b := &Body{Position: mgl32.Vec3{0.5, 10, 0.5}, Width: 0.6, Height: 1.8}
+	Simulate(terrain, []*Body{b}, tick, 120)
+	if !b.OnGround {
+		t.Fatal("body is not on the ground after two seconds")
+	}
+	if y := b.Position.Y(); y < 1 || y > 1.1 {
+		t.Errorf("body rests at y=%v, want just above 1", y)
+	}
+	if b.Velocity.Y() != 0 {
+		t.Errorf("vertical velocity %v after landing, want 0", b.Velocity.Y())
+	}
+	if b.Collides(terrain, b.Position) {
+		t.Error("body ended up inside the floor")
+	}
+}
+
+func TestWallSlide(t *testing.T) {
+	terrain := NewVoxelMap(0)
+	terrain.Fill(2, 0, -10, 2, 3, 10, true) // Wall along z at x=2
+	b := &Body{Position: mgl32.Vec3{1, 0, 0}, Velocity: mgl32.Vec3{4, 0, 3}, Width: 0.6, Height: 1.8}
+	for i := 0; i < 30; i++ {
+		b.Velocity[0], b.Velocity[2] = 4, 3 // Keep walking into the wall
+		b.Step(terrain, tick)
+	}
+	if x := b.Position.X(); x > 2-b.Width/2 || x < 1.6 {
+		t.Errorf("body stopped at x=%v, want just before the wall", x)
+	}
+	if b.Velocity.X() != 0 {
+		t.Errorf("blocked x velocity is %v, want 0", b.Velocity.X())
+	}
+	if b.Velocity.Z() != 3 {
+		t.Errorf("free z velocity is %v, want 3", b.Velocity.Z())
+	}
+	if z := b.Position.Z(); z < 1.4 {
+		t.Errorf("body slid to z=%v, want about 1.5", z)
+	}
+}
+
+func TestPush(t *testing.T) {
+	terrain := NewVoxelMap(0)
+	tests := []struct {
+		name string
+		a, b mgl32.Vec3
+	}{
+		{"overlapping", mgl32.Vec3{0, 0, 0}, mgl32.Vec3{0.3, 0, 0.1}},
+		{"same position", mgl32.Vec3{0, 0, 0}, mgl32.Vec3{0, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Body{Position: tt.a, Width: 0.6, Height: 1.8}
+			b := &Body{Position: tt.b, Width: 0.6, Height: 1.8}
+			Push(terrain, a, b, 1)
+			d := b.Position.Sub(a.Position)
+			if dist := (mgl32.Vec2{d.X(), d.Z()}).Len(); dist < 0.6-1e-4 {
+				t.Errorf("bodies are %v apart after the push, want at least 0.6", dist)
+			}
+			if a.Position.Y() != 0 || b.Position.Y() != 0 {
+				t.Error("push moved a body vertically")
+			}
+		})
+	}
+
+	t.Run("blocked by terrain", func(t *testing.T) {
+		walled := NewVoxelMap(0)
+		walled.Set(-1, 0, 0, true)
+		a := &Body{Position: mgl32.Vec3{0.35, 0, 0.5}, Width: 0.6, Height: 1.8}
+		b := &Body{Position: mgl32.Vec3{0.75, 0, 0.5}, Width: 0.6, Height: 1.8}
+		if a.Collides(walled, a.Position) {
+			t.Fatal("body starts inside the wall")
+		}
+		Push(walled, a, b, 1)
+		if a.Position.X() != 0.35 {
+			t.Errorf("body against the wall moved to x=%v", a.Position.X())
+		}
+		if b.Position.X() <= 0.75 {
+			t.Errorf("free body did not move away, x=%v", b.Position.X())
+		}
+	})
+}
+
+func TestSimulateSeparatesBodies(t *testing.T) {
+	terrain := NewVoxelMap(0)
+	a := &Body{Position: mgl32.Vec3{0.5, 3, 0.5}, Width: 0.6, Height: 1.8}
+	b := &Body{Position: mgl32.Vec3{0.6, 3, 0.5}, Width: 0.6, Height: 1.8}
+	Simulate(terrain, []*Body{a, b}, tick, 60)
+	if !a.OnGround || !b.OnGround {
+		t.Error("bodies did not land")
+	}
+	if d := b.Position.X() - a.Position.X(); d < 0.6-1e-4 {
+		t.Errorf("bodies are %v apart, want at least 0.6", d)
+	}
+}
@@ -0,0 +1,35 @@
+package physics
+
+// VoxelMap is an in-memory Terrain for headless simulations and tools.
+type VoxelMap struct {
+	Solid  map[[3]int]bool
+	Ground int // Every y below Ground is solid; use a large negative value for none
+}
+
+func NewVoxelMap(ground int) *VoxelMap {
+	return &VoxelMap{Solid: make(map[[3]int]bool), Ground: ground}
+}
+
+// Set marks a voxel as solid or empty.
+func (m *VoxelMap) Set(x, y, z int, solid bool) {
+	if solid {
+		m.Solid[[3]int{x, y, z}] = true
+	} else {
+		delete(m.Solid, [3]int{x, y, z})
+	}
+}
+
+// Fill sets every voxel in the inclusive box between two corners.
+func (m *VoxelMap) Fill(x0, y0, z0, x1, y1, z1 int, solid bool) {
+	for x := min(x0, x1); x <= max(x0, x1); x++ {
+		for y := min(y0, y1); y <= max(y0, y1); y++ {
+			for z := min(z0, z1); z <= max(z0, z1); z++ {
+				m.Set(x, y, z, solid)
+			}
+		}
+	}
+}
+
+func (m *VoxelMap) IsSolid(x, y, z int) bool {
+	return y < m.Ground || m.Solid[[3]int{x, y, z}]
+}
@@ -65,6 +65,13 @@ func (c *Camera) ProcessMouse(xoffset, yoffset float64) {
 	c.updateCameraVectors()
 }
 
+// SetRotation points the camera by yaw and pitch in degrees.
+func (c *Camera) SetRotation(yaw, pitch float32) {
+	c.Yaw = yaw
+	c.Pitch = max(-89, min(89, pitch))
+	c.updateCameraVectors()
+}
+
 func (c *Camera) updateCameraVectors() {
 	front := mgl32.Vec3{
 		float32(math.Cos(float64(mgl32.DegToRad(c.Yaw))) * math.Cos(float64(mgl32.DegToRad(c.Pitch)))),
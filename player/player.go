@@ -1,8 +1,15 @@
 package player
 
 import (
+	"fmt"
 	"math"
+
 	"something/block"
+	"something/inventory"
+	"something/item"
+	"something/metrics"
+	"something/physics"
+	"something/profile"
 	aaa "something/world"
 
 	"github.com/go-gl/glfw/v3.3/glfw"
@@ -10,27 +17,36 @@ import (
 )
 
 type Player struct {
-	Camera   *Camera
-	Position mgl32.Vec3
-	Velocity mgl32.Vec3
-	OnGround bool
-	Height   float32
-	Width    float32
+	physics.Body // Position, Velocity, OnGround, Height and Width
+	Camera       *Camera
+	Reach        float32 // Max distance for breaking/placing blocks
+	Inventory    *inventory.Inventory
+	IgnoreInput  bool   // Set while a UI such as the console has keyboard focus
+	Target       [3]int // Block under the crosshair, valid when HasTarget
+	HasTarget    bool
 }
 
 func NewPlayer(position mgl32.Vec3) *Player {
 	return &Player{
-		Camera:   NewCamera(position.Add(mgl32.Vec3{0, 1.5, 0})),
-		Position: position,
-		Velocity: mgl32.Vec3{0, 0, 0},
-		OnGround: false,
-		Height:   1.8,
-		Width:    0.5,
+		Body: physics.Body{
+			Position: position,
+			Velocity: mgl32.Vec3{0, 0, 0},
+			OnGround: false,
+			Height:   1.8,
+			Width:    0.5,
+		},
+		Camera:    NewCamera(position.Add(mgl32.Vec3{0, 1.5, 0})),
+		Reach:     5,
+		Inventory: inventory.NewInventory(),
 	}
 }
 
 func (p *Player) Update(window *glfw.Window, world *aaa.World, deltaTime float32) {
+	defer profile.Begin("player.update")()
 	speed := float32(10.0)
+	if p.IgnoreInput {
+		speed = 0
+	}
 	if window.GetKey(glfw.KeyW) == glfw.Press {
 		p.Velocity = p.Velocity.Add(p.Camera.Front.Mul(speed * deltaTime))
 	}
@@ -43,65 +59,89 @@ func (p *Player) Update(window *glfw.Window, world *aaa.World, deltaTime float32
 	if window.GetKey(glfw.KeyD) == glfw.Press {
 		p.Velocity = p.Velocity.Add(p.Camera.Right.Mul(speed * deltaTime))
 	}
-	if window.GetKey(glfw.KeySpace) == glfw.Press && p.OnGround {
+	if window.GetKey(glfw.KeySpace) == glfw.Press && p.OnGround && !p.IgnoreInput {
 		p.Velocity[1] = 8.0
 		p.OnGround = false
 	}
 
-	gravity := float32(-25.0)
-	p.Velocity[1] += gravity * deltaTime
-	p.move(world, deltaTime)
+	p.Step(world, deltaTime)
 	p.Camera.Position = p.Position.Add(mgl32.Vec3{0, p.Height - 0.2, 0})
+	p.reportMetrics(world)
 }
 
-func (p *Player) move(world *aaa.World, deltaTime float32) {
-	newPos := p.Position
-	steps := []mgl32.Vec3{
-		{p.Velocity[0] * deltaTime, 0, 0},
-		{0, p.Velocity[1] * deltaTime, 0},
-		{0, 0, p.Velocity[2] * deltaTime},
-	}
-	for _, step := range steps {
-		testPos := newPos.Add(step)
-		if !p.checkCollision(world, testPos) {
-			newPos = testPos
-		} else if step[1] < 0 {
-			p.Velocity[1] = 0
-			p.OnGround = true
-		}
+// reportMetrics publishes the player's location and target for the debug screen.
+func (p *Player) reportMetrics(world *aaa.World) {
+	x, y, z := int(math.Floor(float64(p.Position.X()))), int(math.Floor(float64(p.Position.Y()))), int(math.Floor(float64(p.Position.Z())))
+	cx, cz := int(math.Floor(float64(x)/aaa.ChunkSize)), int(math.Floor(float64(z)/aaa.ChunkSize))
+	metrics.SetText(metrics.PlayerPos, fmt.Sprintf("X: %.1f Y: %.1f Z: %.1f", p.Position.X(), p.Position.Y(), p.Position.Z()))
+	metrics.SetText(metrics.PlayerChunk, fmt.Sprintf("Chunk: %d, %d  Local: %d, %d, %d", cx, cz, x-cx*aaa.ChunkSize, y, z-cz*aaa.ChunkSize))
+	metrics.SetText(metrics.PlayerFacing, fmt.Sprintf("Facing: %s (yaw %.1f, pitch %.1f)", facing(p.Camera.Front), p.Camera.Yaw, p.Camera.Pitch))
+	metrics.SetText(metrics.PlayerBiome, "Biome: "+world.Biome(x, z))
+	target := "Target: none"
+	p.Target, _, p.HasTarget = world.Raycast(p.Camera.Position, p.Camera.Front, p.Reach)
+	if hit := p.Target; p.HasTarget {
+		target = fmt.Sprintf("Target: %s at %d, %d, %d", block.Names[world.GetBlock(hit[0], hit[1], hit[2])], hit[0], hit[1], hit[2])
 	}
-	p.Position = newPos
+	metrics.SetText(metrics.PlayerTarget, target)
 }
 
-func (p *Player) checkCollision(world *aaa.World, pos mgl32.Vec3) bool {
-	minX := int(math.Floor(float64(pos.X() - p.Width/2)))
-	maxX := int(math.Floor(float64(pos.X() + p.Width/2)))
-	minY := int(math.Floor(float64(pos.Y())))
-	maxY := int(math.Floor(float64(pos.Y() + p.Height)))
-	minZ := int(math.Floor(float64(pos.Z() - p.Width/2)))
-	maxZ := int(math.Floor(float64(pos.Z() + p.Width/2)))
-	for x := minX; x <= maxX; x++ {
-		for y := minY; y <= maxY; y++ {
-			for z := minZ; z <= maxZ; z++ {
-				chunkX, chunkZ := x/int(aaa.ChunkSize), z/int(aaa.ChunkSize)
-				localX, localY, localZ := x%int(aaa.ChunkSize), y, z%int(aaa.ChunkSize)
-				if localX < 0 {
-					localX += int(aaa.ChunkSize)
-					chunkX--
-				}
-				if localZ < 0 {
-					localZ += int(aaa.ChunkSize)
-					chunkZ--
-				}
-				if localY < 0 || localY >= int(aaa.ChunkSize) {
-					continue
-				}
-				chunk, exists := world.Chunks[[2]int{chunkX, chunkZ}]
-				if exists && chunk.Blocks[localX][localY][localZ] != block.BlockAir {
-					return true
-				}
-			}
+// facing names the horizontal direction of a view vector; -Z is north.
+func facing(front mgl32.Vec3) string {
+	if math.Abs(float64(front.X())) > math.Abs(float64(front.Z())) {
+		if front.X() > 0 {
+			return "east"
 		}
+		return "west"
+	}
+	if front.Z() > 0 {
+		return "south"
+	}
+	return "north"
+}
+
+// BreakBlock removes the targeted block and adds its item to the inventory.
+// It returns the block that was broken.
+func (p *Player) BreakBlock(world *aaa.World) (block.BlockID, bool) {
+	hit, _, ok := world.Raycast(p.Camera.Position, p.Camera.Front, p.Reach)
+	if !ok {
+		return block.BlockAir, false
+	}
+	id := world.GetBlock(hit[0], hit[1], hit[2])
+	if !world.SetBlock(hit[0], hit[1], hit[2], block.BlockAir) {
+		return block.BlockAir, false
+	}
+	if itemID, ok := item.FromBlock(id); ok {
+		p.Inventory.Add(item.NewStack(itemID, 1)) // Overflow is lost while there is no item entity
 	}
-	return false
+	return id, true
+}
+
+// PlaceBlock places the selected hotbar block against the targeted face.
+// It returns the block that was placed.
+func (p *Player) PlaceBlock(world *aaa.World) (block.BlockID, bool) {
+	id, ok := p.Inventory.SelectedBlock()
+	if !ok {
+		return block.BlockAir, false
+	}
+	_, prev, ok := world.Raycast(p.Camera.Position, p.Camera.Front, p.Reach)
+	if !ok || world.GetBlock(prev[0], prev[1], prev[2]) != block.BlockAir {
+		return block.BlockAir, false
+	}
+	if !world.SetBlock(prev[0], prev[1], prev[2], id) {
+		return block.BlockAir, false
+	}
+	if p.Collides(world, p.Position) {
+		world.SetBlock(prev[0], prev[1], prev[2], block.BlockAir) // Don't place inside the player
+		return block.BlockAir, false
+	}
+	p.Inventory.ConsumeSelected()
+	return id, true
+}
+
+// Respawn moves the player back to pos and clears its velocity.
+func (p *Player) Respawn(pos mgl32.Vec3) {
+	p.Position = pos
+	p.Velocity = mgl32.Vec3{0, 0, 0}
+	p.OnGround = false
+	p.Camera.Position = p.Position.Add(mgl32.Vec3{0, p.Height - 0.2, 0})
 }
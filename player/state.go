@@ -0,0 +1,32 @@
+package player
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// State is where a player is, saved with the world so tools such as the
+// web map can show it. The game does not read it back.
+type State struct {
+	Position [3]float32 `json:"position"`
+	Yaw      float32    `json:"yaw"` // Degrees; 0 faces +X, 90 faces +Z
+}
+
+// State returns the player's current state.
+func (p *Player) State() State {
+	return State{Position: p.Position, Yaw: p.Camera.Yaw}
+}
+
+// SaveState writes the player's state as JSON.
+func (p *Player) SaveState(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create player directory: %w", err)
+	}
+	data, err := json.MarshalIndent(p.State(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode player state: %w", err)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,198 @@
+// Package profile times named scopes per frame for the debug overlay and
+// can record them as a Chrome trace (chrome://tracing, Perfetto).
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	smoothing       = 0.1     // Weight of the newest frame in the averaged self times
+	maxTraceEvents  = 1 << 20 // Recording stops after this many events
+	tracePID        = 1
+	traceMainThread = 1
+)
+
+// Section is a scope's self time: its duration minus nested scopes.
+type Section struct {
+	Name string
+	Self time.Duration
+}
+
+// Profiler accumulates scope timings on a single thread.
+type Profiler struct {
+	mu       sync.Mutex
+	order    []string                 // Scope names in first-seen order
+	frame    map[string]time.Duration // Self time in the current frame
+	average  map[string]float64       // Smoothed self time in seconds
+	stack    []*scope
+	start    time.Time // Trace time origin
+	tracing  bool
+	events   []traceEvent
+	overflow bool
+}
+
+type scope struct {
+	name     string
+	begin    time.Time
+	children time.Duration
+}
+
+// traceEvent is a Chrome trace "complete" event; times are microseconds.
+type traceEvent struct {
+	Name     string  `json:"name"`
+	Phase    string  `json:"ph"`
+	Time     float64 `json:"ts"`
+	Duration float64 `json:"dur"`
+	PID      int     `json:"pid"`
+	TID      int     `json:"tid"`
+}
+
+// Default is the profiler used by the package-level functions.
+var Default = New()
+
+func New() *Profiler {
+	return &Profiler{
+		frame:   make(map[string]time.Duration),
+		average: make(map[string]float64),
+		start:   time.Now(),
+	}
+}
+
+// Begin starts timing a scope and returns the function that ends it:
+//
+//	defer profile.Begin("world.render")()
+func (p *Profiler) Begin(name string) func() {
+	p.mu.Lock()
+	p.stack = append(p.stack, &scope{name: name, begin: time.Now()})
+	p.mu.Unlock()
+	return p.end
+}
+
+func (p *Profiler) end() {
+	now := time.Now()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.stack) == 0 {
+		return
+	}
+	s := p.stack[len(p.stack)-1]
+	p.stack = p.stack[:len(p.stack)-1]
+	total := now.Sub(s.begin)
+	if _, seen := p.average[s.name]; !seen {
+		p.order = append(p.order, s.name)
+		p.average[s.name] = 0
+	}
+	p.frame[s.name] += total - s.children
+	if len(p.stack) > 0 {
+		p.stack[len(p.stack)-1].children += total
+	}
+	if p.tracing {
+		if len(p.events) >= maxTraceEvents {
+			p.overflow = true
+			return
+		}
+		p.events = append(p.events, traceEvent{
+			Name:     s.name,
+			Phase:    "X",
+			Time:     float64(s.begin.Sub(p.start).Nanoseconds()) / 1e3,
+			Duration: float64(total.Nanoseconds()) / 1e3,
+			PID:      tracePID,
+			TID:      traceMainThread,
+		})
+	}
+}
+
+// EndFrame folds the frame's self times into the smoothed averages.
+func (p *Profiler) EndFrame() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, name := range p.order {
+		self := p.frame[name].Seconds()
+		p.average[name] += (self - p.average[name]) * smoothing
+		delete(p.frame, name)
+	}
+}
+
+// Sections returns the smoothed self time of every scope seen so far.
+func (p *Profiler) Sections() []Section {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	sections := make([]Section, len(p.order))
+	for i, name := range p.order {
+		sections[i] = Section{Name: name, Self: time.Duration(p.average[name] * float64(time.Second))}
+	}
+	return sections
+}
+
+// StartTrace begins recording every scope for WriteTrace.
+func (p *Profiler) StartTrace() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tracing = true
+	p.events = p.events[:0]
+	p.overflow = false
+	p.start = time.Now()
+}
+
+// StopTrace stops recording; recorded events are kept for WriteTrace.
+func (p *Profiler) StopTrace() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tracing = false
+}
+
+// Tracing reports whether a trace is being recorded.
+func (p *Profiler) Tracing() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.tracing
+}
+
+// WriteTrace writes the recorded events in Chrome trace JSON format.
+func (p *Profiler) WriteTrace(w io.Writer) error {
+	p.mu.Lock()
+	events := append([]traceEvent(nil), p.events...)
+	overflow := p.overflow
+	p.mu.Unlock()
+	trace := struct {
+		TraceEvents     []traceEvent      `json:"traceEvents"`
+		DisplayTimeUnit string            `json:"displayTimeUnit"`
+		Metadata        map[string]string `json:"otherData,omitempty"`
+	}{TraceEvents: events, DisplayTimeUnit: "ms"}
+	if overflow {
+		trace.Metadata = map[string]string{"truncated": fmt.Sprintf("recording stopped after %d events", maxTraceEvents)}
+	}
+	if trace.TraceEvents == nil {
+		trace.TraceEvents = []traceEvent{}
+	}
+	return json.NewEncoder(w).Encode(trace)
+}
+
+// SaveTrace writes the recorded events to a file, creating its directory.
+func (p *Profiler) SaveTrace(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create trace directory: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create trace: %w", err)
+	}
+	if err := p.WriteTrace(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write trace: %w", err)
+	}
+	return f.Close()
+}
+
+// Begin starts a scope on the default profiler.
+func Begin(name string) func() { return Default.Begin(name) }
+
+// EndFrame finishes the default profiler's frame.
+func EndFrame() { Default.EndFrame() }
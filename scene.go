@@ -0,0 +1,34 @@
+package main
+
+import (
+	"something/entities"
+	"something/shadow"
+	"something/world"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// scene draws one view of the world. The game, its captures and headless
+// screenshots share it so they look the same.
+type scene struct {
+	world    *world.World
+	shadows  *shadow.Map
+	entities *entities.Manager // nil draws terrain only
+}
+
+// draw renders the shadow pass, sky, terrain and entities seen from eye
+// through view with the given vertical field of view (radians).
+func (s *scene) draw(eye mgl32.Vec3, view mgl32.Mat4, fovY, aspect float32) {
+	projection := mgl32.Perspective(fovY, aspect, nearPlane, farPlane)
+	casters := []shadow.Caster{s.world.RenderDepth}
+	if s.entities != nil {
+		casters = append(casters, s.entities.RenderDepth)
+	}
+	s.shadows.Update(view, fovY, aspect, nearPlane, s.world.Sky().LightDir)
+	s.shadows.Render(casters...)
+	s.world.RenderSky(view, projection)
+	s.world.Render(view, projection, eye)
+	if s.entities != nil {
+		s.entities.Render(view, projection)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"something/capture"
+	"something/player"
+	"something/shadow"
+	"something/world"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var (
+	screenshotPath = flag.String("screenshot", "", "render one frame to this PNG without starting the game, then exit")
+	panoramaFlag   = flag.Bool("panorama", false, "with -screenshot, write the six cube faces and a cross image into that directory instead")
+	cameraFlag     = flag.String("camera", "0,40,0,-90,-20", "camera for -screenshot as x,y,z,yaw,pitch (degrees)")
+	sizeFlag       = flag.String("size", "1280x720", "image size for -screenshot as WxH; panoramas use the height")
+	timeFlag       = flag.String("time", "noon", "time of day for -screenshot: an hour or sunrise, day, noon, sunset, night, midnight")
+)
+
+// screenshotRadius is the chunk radius loaded for headless screenshots;
+// LOD tiles cover the rest.
+const screenshotRadius = 6
+
+// renderScreenshot renders the terrain from the -camera flag into an
+// offscreen target and writes it to path. It loads no saves or entities,
+// so the same flags always give the same image.
+func renderScreenshot(path string) error {
+	eye, yaw, pitch, err := parseCamera(*cameraFlag)
+	if err != nil {
+		return err
+	}
+	width, height, err := parseSize(*sizeFlag)
+	if err != nil {
+		return err
+	}
+	hour, ok := world.TimesOfDay[*timeFlag]
+	if !ok {
+		if hour, err = strconv.ParseFloat(*timeFlag, 64); err != nil {
+			return fmt.Errorf("invalid time %q", *timeFlag)
+		}
+	}
+	quality, err := shadow.ParseQuality(*shadowFlag)
+	if err != nil {
+		return err
+	}
+
+	if err := glfw.Init(); err != nil {
+		return fmt.Errorf("failed to initialize glfw: %w", err)
+	}
+	defer glfw.Terminate()
+	glfw.WindowHint(glfw.ContextVersionMajor, 4)
+	glfw.WindowHint(glfw.ContextVersionMinor, 6)
+	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
+	glfw.WindowHint(glfw.Visible, glfw.False)
+	window, err := glfw.CreateWindow(64, 64, "screenshot", nil, nil)
+	if err != nil {
+		return err
+	}
+	window.MakeContextCurrent()
+	if err := gl.Init(); err != nil {
+		return err
+	}
+	gl.Enable(gl.DEPTH_TEST)
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+
+	gameWorld := world.World{
+		Chunks:      make(map[[2]int]*world.Chunk),
+		ChunkRadius: screenshotRadius,
+		Seed:        *seedFlag,
+		LODDistance: 384,
+	}
+	if err := gameWorld.Init(); err != nil {
+		return err
+	}
+	defer gameWorld.Cleanup()
+	gameWorld.SetTimeOfDay(hour)
+	shadows, err := shadow.New(quality)
+	if err != nil {
+		return err
+	}
+	defer shadows.Cleanup()
+	gameWorld.Shadows = shadows
+	// Unlimited per-frame budgets load everything in one update
+	gameWorld.UpdateChunks(eye)
+
+	s := &scene{world: &gameWorld, shadows: shadows}
+	if *panoramaFlag {
+		faces, err := capture.Cubemap(height, eye, func(view mgl32.Mat4, fovY, aspect float32) {
+			s.draw(eye, view, fovY, aspect)
+		})
+		if err != nil {
+			return err
+		}
+		return capture.SaveCubemap(path, faces)
+	}
+	camera := player.NewCamera(eye)
+	camera.SetRotation(yaw, pitch)
+	target, err := capture.NewTarget(width, height)
+	if err != nil {
+		return err
+	}
+	defer target.Cleanup()
+	img := target.Render(func() {
+		s.draw(eye, camera.GetViewMatrix(), mgl32.DegToRad(fovY), float32(width)/float32(height))
+	})
+	return capture.Save(img, path)
+}
+
+// parseCamera reads "x,y,z,yaw,pitch".
+func parseCamera(s string) (mgl32.Vec3, float32, float32, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 5 {
+		return mgl32.Vec3{}, 0, 0, fmt.Errorf("camera %q: want x,y,z,yaw,pitch", s)
+	}
+	var v [5]float32
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 32)
+		if err != nil {
+			return mgl32.Vec3{}, 0, 0, fmt.Errorf("camera %q: invalid number %q", s, p)
+		}
+		v[i] = float32(f)
+	}
+	return mgl32.Vec3{v[0], v[1], v[2]}, v[3], v[4], nil
+}
+
+// parseSize reads "WxH".
+func parseSize(s string) (int, int, error) {
+	w, h, ok := strings.Cut(s, "x")
+	width, errW := strconv.Atoi(w)
+	height, errH := strconv.Atoi(h)
+	if !ok || errW != nil || errH != nil || width <= 0 || height <= 0 {
+		return 0, 0, fmt.Errorf("size %q: want WxH", s)
+	}
+	return width, height, nil
+}
+
+// saveScreenshot writes the window's back buffer, as drawn so far this
+// frame, to a timestamped PNG in capture.Dir.
+func saveScreenshot(window *glfw.Window) (string, error) {
+	width, height := window.GetFramebufferSize()
+	path := capture.TimestampPath(capture.Dir, time.Now(), ".png")
+	return path, capture.Save(capture.ReadPixels(width, height), path)
+}
+
+// savePanorama renders the six cube faces around eye into a timestamped
+// directory in capture.Dir.
+func savePanorama(s *scene, eye mgl32.Vec3, size int) (string, error) {
+	faces, err := capture.Cubemap(size, eye, func(view mgl32.Mat4, fovY, aspect float32) {
+		s.draw(eye, view, fovY, aspect)
+	})
+	if err != nil {
+		return "", err
+	}
+	dir := capture.TimestampPath(capture.Dir, time.Now(), "")
+	return dir, capture.SaveCubemap(dir, faces)
+}
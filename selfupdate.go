@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"something/update"
+)
+
+// version is the build's release version, set by release builds with
+// -ldflags "-X main.version=1.2.0".
+var version = "dev"
+
+var (
+	versionFlag   = flag.Bool("version", false, "print the build version and exit")
+	updateServer  = flag.String("update-server", "", "web server to check for a newer release at startup, e.g. http://localhost:8080")
+	updateChannel = flag.String("update-channel", "", "release channel to follow; empty follows the server's default")
+	rollbackFlag  = flag.Bool("rollback", false, "restore the build replaced by the last update and exit")
+)
+
+// updateTimeout bounds the startup update, including the download.
+const updateTimeout = 5 * time.Minute
+
+// selfUpdate installs a newer release from -update-server, if there is one,
+// and restarts into it. It reports whether this process should exit.
+func selfUpdate() (bool, error) {
+	if version == "dev" {
+		log.Printf("skipping update check in a development build")
+		return false, nil
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return false, fmt.Errorf("failed to find executable: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), updateTimeout)
+	defer cancel()
+	u := &update.Updater{
+		Client:  &update.Client{BaseURL: *updateServer, Channel: *updateChannel},
+		Current: version,
+		Exe:     exe,
+	}
+	installed, err := u.Update(ctx)
+	if err != nil || installed == "" {
+		return false, err
+	}
+	log.Printf("updated from %s to %s; restarting", version, installed)
+	return true, update.Restart(exe, os.Args[1:])
+}
+
+// rollback restores the previous build in place of this one.
+func rollback() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find executable: %w", err)
+	}
+	if err := update.Rollback(exe); err != nil {
+		return err
+	}
+	log.Printf("restored the previous build; restart to use it")
+	return nil
+}
+
+// checkStartup rolls back an update whose previous launch never confirmed a
+// healthy startup, and restarts into the restored build. It reports whether
+// this process should exit.
+func checkStartup() (bool, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return false, fmt.Errorf("failed to find executable: %w", err)
+	}
+	rolledBack, err := update.CheckStartup(exe, version)
+	if err != nil || !rolledBack {
+		return false, err
+	}
+	log.Printf("build %s did not start successfully last time; restarting into the previous build", version)
+	return true, update.Restart(exe, os.Args[1:])
+}
+
+// confirmStartup tells the updater that this build started successfully.
+func confirmStartup() {
+	exe, err := os.Executable()
+	if err == nil {
+		err = update.ConfirmStartup(exe)
+	}
+	if err != nil {
+		log.Printf("failed to confirm startup: %v", err)
+	}
+}
@@ -0,0 +1,288 @@
+// Package shader loads GLSL programs from .vert and .frag files with
+// #include support, caches uniform locations and hot-reloads programs when
+// their files change on disk.
+package shader
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Dir is where shader files live.
+const Dir = "assets/shaders"
+
+// Program is a linked vertex and fragment shader pair. ID changes when the
+// program is reloaded, so hold the *Program rather than the ID.
+type Program struct {
+	ID           uint32
+	Name         string // Base name of the files, e.g. "chunk"
+	VertexPath   string
+	FragmentPath string
+	OnReload     func() // Called after a successful reload; may be nil
+
+	library  *Library
+	uniforms map[string]int32
+	modTimes map[string]time.Time // Every file read, including includes
+}
+
+// build compiles and links the program's files without touching p.
+func (p *Program) build() (uint32, map[string]time.Time, error) {
+	vert, err := preprocess(p.VertexPath)
+	if err != nil {
+		return 0, nil, err
+	}
+	frag, err := preprocess(p.FragmentPath)
+	if err != nil {
+		return 0, nil, err
+	}
+	vertexShader, err := compile(vert, gl.VERTEX_SHADER)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer gl.DeleteShader(vertexShader)
+	fragmentShader, err := compile(frag, gl.FRAGMENT_SHADER)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer gl.DeleteShader(fragmentShader)
+
+	id := gl.CreateProgram()
+	gl.AttachShader(id, vertexShader)
+	gl.AttachShader(id, fragmentShader)
+	gl.LinkProgram(id)
+	var status int32
+	gl.GetProgramiv(id, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetProgramiv(id, gl.INFO_LOG_LENGTH, &logLength)
+		log := make([]byte, logLength+1)
+		gl.GetProgramInfoLog(id, logLength, nil, &log[0])
+		gl.DeleteProgram(id)
+		return 0, nil, fmt.Errorf("failed to link %s: %s", p.Name, strings.TrimRight(string(log), "\x00\n"))
+	}
+
+	modTimes := make(map[string]time.Time)
+	for _, file := range append(vert.files, frag.files...) {
+		if info, err := os.Stat(file); err == nil {
+			modTimes[file] = info.ModTime()
+		}
+	}
+	return id, modTimes, nil
+}
+
+func compile(src *source, shaderType uint32) (uint32, error) {
+	shader := gl.CreateShader(shaderType)
+	csources, free := gl.Strs(src.text + "\x00")
+	gl.ShaderSource(shader, 1, csources, nil)
+	free()
+	gl.CompileShader(shader)
+
+	var status int32
+	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
+		log := make([]byte, logLength+1)
+		gl.GetShaderInfoLog(shader, logLength, nil, &log[0])
+		gl.DeleteShader(shader)
+		return 0, fmt.Errorf("failed to compile shader:\n%s", src.annotate(strings.TrimRight(string(log), "\x00")))
+	}
+	return shader, nil
+}
+
+// Reload rebuilds the program from its files. On error the old program
+// stays in use.
+func (p *Program) Reload() error {
+	id, modTimes, err := p.build()
+	if err != nil {
+		return err
+	}
+	if p.ID != 0 {
+		gl.DeleteProgram(p.ID)
+	}
+	p.ID, p.modTimes = id, modTimes
+	p.uniforms = make(map[string]int32)
+	if p.OnReload != nil {
+		p.OnReload()
+	}
+	return nil
+}
+
+// changed reports whether any file the program was built from has a new
+// modification time or has disappeared.
+func (p *Program) changed() bool {
+	for file, t := range p.modTimes {
+		info, err := os.Stat(file)
+		if err != nil || !info.ModTime().Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Use makes the program current.
+func (p *Program) Use() {
+	gl.UseProgram(p.ID)
+}
+
+// Uniform returns a uniform's location, looking it up once per build.
+// Unknown names return -1, which GL ignores.
+func (p *Program) Uniform(name string) int32 {
+	if loc, ok := p.uniforms[name]; ok {
+		return loc
+	}
+	loc := gl.GetUniformLocation(p.ID, gl.Str(name+"\x00"))
+	p.uniforms[name] = loc
+	return loc
+}
+
+// The setters below act on the current program; call Use first.
+
+func (p *Program) SetInt(name string, v int32) {
+	gl.Uniform1i(p.Uniform(name), v)
+}
+
+func (p *Program) SetBool(name string, v bool) {
+	i := int32(0)
+	if v {
+		i = 1
+	}
+	gl.Uniform1i(p.Uniform(name), i)
+}
+
+func (p *Program) SetFloat(name string, v float32) {
+	gl.Uniform1f(p.Uniform(name), v)
+}
+
+func (p *Program) SetFloats(name string, v []float32) {
+	if len(v) > 0 {
+		gl.Uniform1fv(p.Uniform(name), int32(len(v)), &v[0])
+	}
+}
+
+func (p *Program) SetVec2(name string, v mgl32.Vec2) {
+	gl.Uniform2f(p.Uniform(name), v[0], v[1])
+}
+
+func (p *Program) SetVec3(name string, v mgl32.Vec3) {
+	gl.Uniform3f(p.Uniform(name), v[0], v[1], v[2])
+}
+
+func (p *Program) SetVec4(name string, v mgl32.Vec4) {
+	gl.Uniform4f(p.Uniform(name), v[0], v[1], v[2], v[3])
+}
+
+func (p *Program) SetMat4(name string, m mgl32.Mat4) {
+	gl.UniformMatrix4fv(p.Uniform(name), 1, false, &m[0])
+}
+
+func (p *Program) SetMat4s(name string, m []mgl32.Mat4) {
+	if len(m) > 0 {
+		gl.UniformMatrix4fv(p.Uniform(name), int32(len(m)), false, &m[0][0])
+	}
+}
+
+// Delete frees the program and stops watching it.
+func (p *Program) Delete() {
+	if p.library != nil {
+		p.library.remove(p)
+	}
+	gl.DeleteProgram(p.ID)
+	p.ID = 0
+}
+
+// Library tracks loaded programs so they can be reloaded together.
+type Library struct {
+	Dir          string
+	PollInterval time.Duration // Minimum time between file checks in Poll
+	programs     []*Program
+	lastPoll     time.Time
+}
+
+// Default is the library used by the package-level functions.
+var Default = &Library{Dir: Dir, PollInterval: 500 * time.Millisecond}
+
+// Load builds the program from name.vert and name.frag in the library's
+// directory and starts watching its files.
+func (l *Library) Load(name string) (*Program, error) {
+	p := &Program{
+		Name:         name,
+		VertexPath:   filepath.Join(l.Dir, name+".vert"),
+		FragmentPath: filepath.Join(l.Dir, name+".frag"),
+		library:      l,
+	}
+	if err := p.Reload(); err != nil {
+		return nil, fmt.Errorf("failed to load shader %s: %w", name, err)
+	}
+	l.programs = append(l.programs, p)
+	return p, nil
+}
+
+// ReloadAll rebuilds every program, keeping the old build of any that fail.
+func (l *Library) ReloadAll() error {
+	var errs []error
+	for _, p := range l.programs {
+		if err := p.Reload(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Poll reloads programs whose files changed since they were built. It
+// checks at most once per PollInterval and returns the names reloaded and
+// any build errors.
+func (l *Library) Poll() (reloaded []string, err error) {
+	if time.Since(l.lastPoll) < l.PollInterval {
+		return nil, nil
+	}
+	l.lastPoll = time.Now()
+	var errs []error
+	for _, p := range l.programs {
+		if !p.changed() {
+			continue
+		}
+		if err := p.Reload(); err != nil {
+			// Remember the broken files so the error is reported once per save
+			p.refreshModTimes()
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name, err))
+			continue
+		}
+		reloaded = append(reloaded, p.Name)
+	}
+	return reloaded, errors.Join(errs...)
+}
+
+// refreshModTimes records the current times of the watched files.
+func (p *Program) refreshModTimes() {
+	for file := range p.modTimes {
+		if info, err := os.Stat(file); err == nil {
+			p.modTimes[file] = info.ModTime()
+		}
+	}
+}
+
+func (l *Library) remove(p *Program) {
+	for i, q := range l.programs {
+		if q == p {
+			l.programs = append(l.programs[:i], l.programs[i+1:]...)
+			return
+		}
+	}
+}
+
+// Load loads a program into the default library.
+func Load(name string) (*Program, error) { return Default.Load(name) }
+
+// ReloadAll rebuilds every program in the default library.
+func ReloadAll() error { return Default.ReloadAll() }
+
+// Poll reloads changed programs in the default library.
+func Poll() ([]string, error) { return Default.Poll() }
@@ -0,0 +1,97 @@
+package shader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// origin is the file and line a line of preprocessed source came from.
+type origin struct {
+	file string
+	line int
+}
+
+// source is a preprocessed shader stage: the text passed to the driver,
+// where each of its lines came from, and every file it read.
+type source struct {
+	text  string
+	lines []origin
+	files []string
+}
+
+var includePattern = regexp.MustCompile(`^\s*#include\s+"([^"]+)"\s*$`)
+
+// preprocess reads a shader file and expands #include "path" directives.
+// Paths are relative to the including file. Each file is included at most
+// once, so shared headers need no guards.
+func preprocess(path string) (*source, error) {
+	s := &source{}
+	var b strings.Builder
+	seen := make(map[string]bool)
+	if err := s.expand(&b, path, seen, nil); err != nil {
+		return nil, err
+	}
+	s.text = b.String()
+	return s, nil
+}
+
+func (s *source) expand(b *strings.Builder, path string, seen map[string]bool, stack []string) error {
+	clean := filepath.Clean(path)
+	for _, p := range stack {
+		if p == clean {
+			return fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), clean)
+		}
+	}
+	if seen[clean] {
+		return nil
+	}
+	seen[clean] = true
+	data, err := os.ReadFile(clean)
+	if err != nil {
+		return fmt.Errorf("failed to read shader: %w", err)
+	}
+	s.files = append(s.files, clean)
+	stack = append(stack, clean)
+	for i, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		if m := includePattern.FindStringSubmatch(line); m != nil {
+			if err := s.expand(b, filepath.Join(filepath.Dir(clean), m[1]), seen, stack); err != nil {
+				return fmt.Errorf("%s:%d: %w", clean, i+1, err)
+			}
+			continue
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+		s.lines = append(s.lines, origin{file: clean, line: i + 1})
+	}
+	return nil
+}
+
+// logLinePattern finds the line number in driver messages, which come as
+// "0:12(5): error" (Mesa), "0(12) : error" (NVIDIA) or "ERROR: 0:12:" (AMD).
+var logLinePattern = regexp.MustCompile(`\b\d+[:(](\d+)\)?(\(\d+\))?\s*:\s*`)
+
+// annotate rewrites a compile log so each message names the file and line
+// it came from instead of a line in the expanded source.
+func (s *source) annotate(log string) string {
+	var out []string
+	for _, line := range strings.Split(strings.TrimSpace(log), "\n") {
+		m := logLinePattern.FindStringSubmatchIndex(line)
+		if m == nil {
+			out = append(out, line)
+			continue
+		}
+		n, _ := strconv.Atoi(line[m[2]:m[3]])
+		if n < 1 || n > len(s.lines) {
+			out = append(out, line)
+			continue
+		}
+		o := s.lines[n-1]
+		msg := strings.TrimSpace(line[:m[0]] + line[m[1]:])
+		out = append(out, fmt.Sprintf("%s:%d: %s", o.file, o.line, msg))
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,121 @@
+package shader
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeShaders writes files into a temporary directory and returns it.
+func writeShaders(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestPreprocess(t *testing.T) {
+	dir := writeShaders(t, map[string]string{
+		"main.frag":       "#version 410\n#include \"lib/light.glsl\"\n  #include \"common.glsl\"  \nvoid main() {}\n",
+		"common.glsl":     "float common;\n",
+		"lib/light.glsl":  "#include \"../common.glsl\"\n#include \"shadow.glsl\"\nfloat light;\n",
+		"lib/shadow.glsl": "float shadow;\n",
+	})
+	s, err := preprocess(filepath.Join(dir, "main.frag"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// common.glsl is included twice but expanded once, at its first include
+	want := "#version 410\nfloat common;\nfloat shadow;\nfloat light;\nvoid main() {}\n"
+	if s.text != want {
+		t.Errorf("text:\n%s\nwant:\n%s", s.text, want)
+	}
+	rel := func(p string) string { return filepath.Join(dir, filepath.FromSlash(p)) }
+	wantLines := []origin{
+		{rel("main.frag"), 1},
+		{rel("common.glsl"), 1},
+		{rel("lib/shadow.glsl"), 1},
+		{rel("lib/light.glsl"), 3},
+		{rel("main.frag"), 4},
+	}
+	if !slices.Equal(s.lines, wantLines) {
+		t.Errorf("lines %v, want %v", s.lines, wantLines)
+	}
+	wantFiles := []string{rel("main.frag"), rel("lib/light.glsl"), rel("common.glsl"), rel("lib/shadow.glsl")}
+	if !slices.Equal(s.files, wantFiles) {
+		t.Errorf("files %v, want %v", s.files, wantFiles)
+	}
+}
+
+func TestPreprocessErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr []string
+	}{
+		{
+			"cycle",
+			map[string]string{
+				"main.frag": "#include \"a.glsl\"\n",
+				"a.glsl":    "float a;\n#include \"b.glsl\"\n",
+				"b.glsl":    "#include \"a.glsl\"\n",
+			},
+			[]string{"main.frag:1:", "a.glsl:2:", "include cycle:", "a.glsl -> ", "b.glsl -> "},
+		},
+		{
+			"includes itself",
+			map[string]string{"main.frag": "#include \"main.frag\"\n"},
+			[]string{"include cycle"},
+		},
+		{
+			"missing include",
+			map[string]string{"main.frag": "void main() {}\n\n#include \"gone.glsl\"\n"},
+			[]string{"main.frag:3:", "failed to read shader"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeShaders(t, tt.files)
+			_, err := preprocess(filepath.Join(dir, "main.frag"))
+			if err == nil {
+				t.Fatal("preprocess succeeded")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	s := &source{lines: []origin{{"main.frag", 1}, {"common.glsl", 1}, {"common.glsl", 2}, {"main.frag", 3}}}
+	tests := []struct {
+		name, log, want string
+	}{
+		{"mesa", "0:3(5): error: syntax error", "common.glsl:2: error: syntax error"},
+		{"nvidia", "0(4) : error C0000: syntax error", "main.frag:3: error C0000: syntax error"},
+		{"amd", "ERROR: 0:2: 'x' : undeclared identifier", "common.glsl:1: ERROR: 'x' : undeclared identifier"},
+		{"line out of range", "0:9(1): error: oops", "0:9(1): error: oops"},
+		{"no line", "Linking failed", "Linking failed"},
+		{"several lines", "0:1(1): warning: a\n0:4(2): error: b\n", "main.frag:1: warning: a\nmain.frag:3: error: b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.annotate(tt.log); got != tt.want {
+				t.Errorf("annotate(%q) = %q, want %q", tt.log, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+// Package shadow renders cascaded shadow maps for a directional light.
+// Casters draw into one depth layer per cascade; receivers sample the
+// layers with PCF through the uniforms set by Bind.
+package shadow
+
+import (
+	"fmt"
+	"math"
+
+	"something/profile"
+	"something/shader"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// MaxCascades is the size of the cascade uniform arrays in receiving shaders.
+const MaxCascades = 4
+
+const (
+	splitLambda  = 0.75 // Blend between logarithmic (1) and uniform (0) cascade splits
+	casterMargin = 32   // Blocks behind each cascade that can still cast into it
+)
+
+// Quality names a preset of shadow settings.
+type Quality string
+
+const (
+	Off    Quality = "off"
+	Low    Quality = "low"
+	Medium Quality = "medium"
+	High   Quality = "high"
+)
+
+// Settings control shadow cost and appearance.
+type Settings struct {
+	Cascades  int     // Number of depth layers; 0 disables shadows
+	Size      int32   // Width and height of each layer in texels
+	PCFRadius int     // Filter taps either side of the sample; 0 is one hardware-filtered tap
+	Distance  float32 // View distance covered by the last cascade
+}
+
+var presets = map[Quality]Settings{
+	Off:    {},
+	Low:    {Cascades: 2, Size: 1024, PCFRadius: 0, Distance: 48},
+	Medium: {Cascades: 3, Size: 2048, PCFRadius: 1, Distance: 64},
+	High:   {Cascades: 4, Size: 2048, PCFRadius: 2, Distance: 96},
+}
+
+// Qualities lists the presets from cheapest to most expensive.
+var Qualities = []Quality{Off, Low, Medium, High}
+
+// ParseQuality validates a preset name.
+func ParseQuality(name string) (Quality, error) {
+	q := Quality(name)
+	if _, ok := presets[q]; !ok {
+		return "", fmt.Errorf("unknown shadow quality %q", name)
+	}
+	return q, nil
+}
+
+// Caster draws depth-only geometry; it calls setModel before each mesh.
+type Caster func(setModel func(model mgl32.Mat4))
+
+// Map owns the cascade depth textures and the light matrices for a frame.
+type Map struct {
+	Quality      Quality
+	Settings     Settings
+	ShowCascades bool         // Receivers tint each cascade a different colour
+	Matrices     []mgl32.Mat4 // World to light clip space, per cascade
+	Splits       []float32    // Far view depth of each cascade
+
+	program *shader.Program // Depth-only shader
+	texture uint32          // Depth texture array, one layer per cascade
+	fbo     uint32
+}
+
+// New creates a shadow map at a quality preset.
+func New(q Quality) (*Map, error) {
+	program, err := shader.Load("shadow_depth")
+	if err != nil {
+		return nil, err
+	}
+	m := &Map{program: program}
+	if err := m.SetQuality(q); err != nil {
+		program.Delete()
+		return nil, err
+	}
+	return m, nil
+}
+
+// SetQuality switches preset, recreating the depth textures.
+func (m *Map) SetQuality(q Quality) error {
+	settings, ok := presets[q]
+	if !ok {
+		return fmt.Errorf("unknown shadow quality %q", q)
+	}
+	m.deleteTargets()
+	m.Quality, m.Settings = q, settings
+	m.Matrices = make([]mgl32.Mat4, settings.Cascades)
+	m.Splits = make([]float32, settings.Cascades)
+	if settings.Cascades == 0 {
+		return nil
+	}
+
+	gl.GenTextures(1, &m.texture)
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, m.texture)
+	gl.TexImage3D(gl.TEXTURE_2D_ARRAY, 0, gl.DEPTH_COMPONENT32F, settings.Size, settings.Size, int32(settings.Cascades),
+		0, gl.DEPTH_COMPONENT, gl.FLOAT, nil)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_BORDER)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_BORDER)
+	border := []float32{1, 1, 1, 1} // Outside the map counts as lit
+	gl.TexParameterfv(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_BORDER_COLOR, &border[0])
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_COMPARE_MODE, gl.COMPARE_REF_TO_TEXTURE)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_COMPARE_FUNC, gl.LEQUAL)
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, 0)
+
+	gl.GenFramebuffers(1, &m.fbo)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, m.fbo)
+	gl.FramebufferTextureLayer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, m.texture, 0, 0)
+	gl.DrawBuffer(gl.NONE)
+	gl.ReadBuffer(gl.NONE)
+	status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	if status != gl.FRAMEBUFFER_COMPLETE {
+		m.deleteTargets()
+		m.Quality, m.Settings = Off, presets[Off]
+		m.Matrices, m.Splits = nil, nil
+		return fmt.Errorf("shadow framebuffer incomplete: 0x%x", status)
+	}
+	return nil
+}
+
+// Enabled reports whether the current quality draws shadows.
+func (m *Map) Enabled() bool {
+	return m != nil && m.Settings.Cascades > 0
+}
+
+// Update fits each cascade's light matrix around its slice of the camera
+// frustum. lightDir is the direction the light travels.
+func (m *Map) Update(view mgl32.Mat4, fovY, aspect, near float32, lightDir mgl32.Vec3) {
+	if !m.Enabled() {
+		return
+	}
+	n := m.Settings.Cascades
+	far := m.Settings.Distance
+	lightDir = lightDir.Normalize()
+	up := mgl32.Vec3{0, 1, 0}
+	if math.Abs(float64(lightDir.Y())) > 0.99 {
+		up = mgl32.Vec3{0, 0, 1}
+	}
+	sliceNear := near
+	for i := 0; i < n; i++ {
+		// Practical split scheme: mostly logarithmic, so near cascades stay sharp
+		p := float32(i+1) / float32(n)
+		logSplit := near * float32(math.Pow(float64(far/near), float64(p)))
+		uniformSplit := near + (far-near)*p
+		sliceFar := splitLambda*logSplit + (1-splitLambda)*uniformSplit
+		m.Splits[i] = sliceFar
+
+		// A bounding sphere keeps the cascade size constant as the camera turns
+		corners := frustumCorners(view, fovY, aspect, sliceNear, sliceFar)
+		var center mgl32.Vec3
+		for _, c := range corners {
+			center = center.Add(c)
+		}
+		center = center.Mul(1.0 / float32(len(corners)))
+		var radius float32
+		for _, c := range corners {
+			radius = max(radius, c.Sub(center).Len())
+		}
+		radius = float32(math.Ceil(float64(radius)))
+
+		eye := center.Sub(lightDir.Mul(radius + casterMargin))
+		lightView := mgl32.LookAtV(eye, center, up)
+		lightProj := mgl32.Ortho(-radius, radius, -radius, radius, 0, 2*radius+casterMargin)
+		matrix := lightProj.Mul4(lightView)
+
+		// Snap the origin to whole texels so shadows do not shimmer as the camera moves
+		half := float32(m.Settings.Size) / 2
+		origin := matrix.Mul4x1(mgl32.Vec4{0, 0, 0, 1}).Mul(half)
+		offset := mgl32.Vec2{float32(math.Round(float64(origin.X()))) - origin.X(), float32(math.Round(float64(origin.Y()))) - origin.Y()}.Mul(1 / half)
+		lightProj.Set(0, 3, lightProj.At(0, 3)+offset.X())
+		lightProj.Set(1, 3, lightProj.At(1, 3)+offset.Y())
+		m.Matrices[i] = lightProj.Mul4(lightView)
+		sliceNear = sliceFar
+	}
+}
+
+// frustumCorners returns the world-space corners of a slice of the view
+// frustum between two view depths.
+func frustumCorners(view mgl32.Mat4, fovY, aspect, near, far float32) []mgl32.Vec3 {
+	inverse := mgl32.Perspective(fovY, aspect, near, far).Mul4(view).Inv()
+	corners := make([]mgl32.Vec3, 0, 8)
+	for _, x := range []float32{-1, 1} {
+		for _, y := range []float32{-1, 1} {
+			for _, z := range []float32{-1, 1} {
+				p := inverse.Mul4x1(mgl32.Vec4{x, y, z, 1})
+				corners = append(corners, p.Vec3().Mul(1/p.W()))
+			}
+		}
+	}
+	return corners
+}
+
+// Render draws every caster into every cascade, then restores the
+// framebuffer and viewport.
+func (m *Map) Render(casters ...Caster) {
+	if !m.Enabled() {
+		return
+	}
+	defer profile.Begin("shadow.render")()
+	var viewport [4]int32
+	var framebuffer int32
+	gl.GetIntegerv(gl.VIEWPORT, &viewport[0])
+	gl.GetIntegerv(gl.FRAMEBUFFER_BINDING, &framebuffer) // Captures render offscreen
+	gl.BindFramebuffer(gl.FRAMEBUFFER, m.fbo)
+	gl.Viewport(0, 0, m.Settings.Size, m.Settings.Size)
+	gl.Enable(gl.POLYGON_OFFSET_FILL)
+	gl.PolygonOffset(2, 4) // Pushes depths back to reduce self-shadowing acne
+	m.program.Use()
+	setModel := func(model mgl32.Mat4) {
+		m.program.SetMat4("model", model)
+	}
+	for i := range m.Matrices {
+		gl.FramebufferTextureLayer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, m.texture, 0, int32(i))
+		gl.Clear(gl.DEPTH_BUFFER_BIT)
+		m.program.SetMat4("lightSpace", m.Matrices[i])
+		for _, cast := range casters {
+			cast(setModel)
+		}
+	}
+	gl.BindVertexArray(0)
+	gl.Disable(gl.POLYGON_OFFSET_FILL)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, uint32(framebuffer))
+	gl.Viewport(viewport[0], viewport[1], viewport[2], viewport[3])
+}
+
+// Bind sets a receiving shader's shadow uniforms and binds the depth
+// textures to a texture unit. It is safe on a nil or disabled map, which
+// sets cascadeCount to 0 so the receiver skips shadowing. The program must
+// be in use.
+func (m *Map) Bind(program *shader.Program, unit uint32) {
+	// The sampler always needs its own unit, even unused, or its type
+	// clashes with the colour sampler on unit 0
+	program.SetInt("shadowMap", int32(unit))
+	if !m.Enabled() {
+		program.SetInt("cascadeCount", 0)
+		program.SetBool("showCascades", false)
+		return
+	}
+	gl.ActiveTexture(gl.TEXTURE0 + unit)
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, m.texture)
+	gl.ActiveTexture(gl.TEXTURE0)
+	program.SetInt("cascadeCount", int32(len(m.Matrices)))
+	program.SetMat4s("lightSpace", m.Matrices)
+	program.SetFloats("cascadeSplits", m.Splits)
+	program.SetInt("pcfRadius", int32(m.Settings.PCFRadius))
+	program.SetBool("showCascades", m.ShowCascades)
+}
+
+func (m *Map) deleteTargets() {
+	if m.fbo != 0 {
+		gl.DeleteFramebuffers(1, &m.fbo)
+		m.fbo = 0
+	}
+	if m.texture != 0 {
+		gl.DeleteTextures(1, &m.texture)
+		m.texture = 0
+	}
+}
+
+func (m *Map) Cleanup() {
+	m.deleteTargets()
+	m.program.Delete()
+}
@@ -1 +1,173 @@
 package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"something/events"
+)
+
+// Stats holds long-running counters for one player in one world.
+type Stats struct {
+	mu              sync.Mutex
+	BlocksMined     map[string]int `json:"blocks_mined"`  // Block name -> count
+	BlocksPlaced    map[string]int `json:"blocks_placed"` // Block name -> count
+	DistanceWalked  float64        `json:"distance_walked"`
+	DistanceFallen  float64        `json:"distance_fallen"`
+	DistanceFlown   float64        `json:"distance_flown"` // Horizontal distance while airborne
+	Jumps           int            `json:"jumps"`
+	PlayTime        float64        `json:"play_time"`        // Seconds
+	ChunksGenerated int            `json:"chunks_generated"` // First generations, not reloads
+	Deaths          int            `json:"deaths"`
+}
+
+func New() *Stats {
+	return &Stats{
+		BlocksMined:  make(map[string]int),
+		BlocksPlaced: make(map[string]int),
+	}
+}
+
+// Subscribe counts block, jump, chunk and death events from the bus.
+func (s *Stats) Subscribe(bus *events.Bus) {
+	bus.Subscribe(events.BlockBroken, func(e events.Event) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.BlocksMined[e.Subject]++
+	})
+	bus.Subscribe(events.BlockPlaced, func(e events.Event) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.BlocksPlaced[e.Subject]++
+	})
+	bus.Subscribe(events.PlayerJumped, func(e events.Event) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.Jumps++
+	})
+	bus.Subscribe(events.PlayerDied, func(e events.Event) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.Deaths++
+	})
+	bus.Subscribe(events.ChunkGenerated, func(e events.Event) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.ChunksGenerated++
+	})
+}
+
+// RecordMovement splits a frame's displacement into walked, flown and
+// fallen distance. Only airborne descent counts as fallen, not walking
+// downhill.
+func (s *Stats) RecordMovement(dx, dy, dz float32, onGround bool) {
+	horizontal := math.Hypot(float64(dx), float64(dz))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if onGround {
+		s.DistanceWalked += horizontal
+	} else {
+		s.DistanceFlown += horizontal
+	}
+	if dy < 0 && !onGround {
+		s.DistanceFallen += float64(-dy)
+	}
+}
+
+// AddPlayTime adds elapsed seconds to the play time counter.
+func (s *Stats) AddPlayTime(seconds float32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.PlayTime += float64(seconds)
+}
+
+// Lines summarizes the counters for on-screen display.
+func (s *Stats) Lines() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lines := []string{
+		fmt.Sprintf("Mined: %d Placed: %d", total(s.BlocksMined), total(s.BlocksPlaced)),
+		fmt.Sprintf("Walked: %.0f Fallen: %.0f Flown: %.0f", s.DistanceWalked, s.DistanceFallen, s.DistanceFlown),
+		fmt.Sprintf("Jumps: %d Deaths: %d Chunks: %d", s.Jumps, s.Deaths, s.ChunksGenerated),
+		fmt.Sprintf("Play time: %dm%02ds", int(s.PlayTime)/60, int(s.PlayTime)%60),
+	}
+	names := make([]string, 0, len(s.BlocksMined))
+	for name := range s.BlocksMined {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("  %s: %d mined, %d placed", name, s.BlocksMined[name], s.BlocksPlaced[name]))
+	}
+	return lines
+}
+
+// WriteJSON exports the counters as JSON.
+func (s *Stats) WriteJSON(w io.Writer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// Load restores counters from a world's stats file. A missing file is not an error.
+func (s *Stats) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read stats: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := json.Unmarshal(data, s); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if s.BlocksMined == nil {
+		s.BlocksMined = make(map[string]int)
+	}
+	if s.BlocksPlaced == nil {
+		s.BlocksPlaced = make(map[string]int)
+	}
+	return nil
+}
+
+// Save writes the counters to a world's stats file. The file is replaced
+// in one rename, so a crash mid-save keeps the previous counters.
+func (s *Stats) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create stats directory: %w", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create stats file: %w", err)
+	}
+	defer os.Remove(f.Name()) // No-op once renamed
+	err = s.WriteJSON(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write stats: %w", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace stats file: %w", err)
+	}
+	return nil
+}
+
+func total(m map[string]int) int {
+	n := 0
+	for _, v := range m {
+		n += v
+	}
+	return n
+}
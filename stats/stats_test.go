@@ -0,0 +1,107 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"something/events"
+)
+
+func TestRecordMovement(t *testing.T) {
+	tests := []struct {
+		name                  string
+		dx, dy, dz            float32
+		onGround              bool
+		walked, flown, fallen float64
+	}{
+		{"walk", 3, 0, 4, true, 5, 0, 0},
+		{"walk downhill", 3, -1, 4, true, 5, 0, 0},
+		{"walk uphill", 0, 1, 2, true, 2, 0, 0},
+		{"jump", 0, 0.5, 0, false, 0, 0, 0},
+		{"fall", 0, -2, 0, false, 0, 0, 2},
+		{"glide", 6, -0.5, 8, false, 0, 10, 0.5},
+		{"stand", 0, 0, 0, true, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New()
+			s.RecordMovement(tt.dx, tt.dy, tt.dz, tt.onGround)
+			if s.DistanceWalked != tt.walked || s.DistanceFlown != tt.flown || s.DistanceFallen != tt.fallen {
+				t.Errorf("walked %v flown %v fallen %v, want %v %v %v",
+					s.DistanceWalked, s.DistanceFlown, s.DistanceFallen, tt.walked, tt.flown, tt.fallen)
+			}
+		})
+	}
+}
+
+func TestBusCounters(t *testing.T) {
+	bus := events.NewBus()
+	s := New()
+	s.Subscribe(bus)
+	for _, e := range []events.Event{
+		{Type: events.BlockBroken, Subject: "stone", Amount: 1},
+		{Type: events.BlockBroken, Subject: "stone", Amount: 1},
+		{Type: events.BlockBroken, Subject: "dirt", Amount: 1},
+		{Type: events.BlockPlaced, Subject: "planks", Amount: 1},
+		{Type: events.PlayerJumped, Amount: 1},
+		{Type: events.PlayerDied, Amount: 1},
+		{Type: events.ChunkGenerated, Amount: 1},
+		{Type: events.ChunkGenerated, Amount: 1},
+		{Type: events.ItemCrafted, Subject: "planks", Amount: 4}, // Not counted
+	} {
+		bus.Publish(e)
+	}
+	if want := map[string]int{"stone": 2, "dirt": 1}; !reflect.DeepEqual(s.BlocksMined, want) {
+		t.Errorf("mined %v, want %v", s.BlocksMined, want)
+	}
+	if want := map[string]int{"planks": 1}; !reflect.DeepEqual(s.BlocksPlaced, want) {
+		t.Errorf("placed %v, want %v", s.BlocksPlaced, want)
+	}
+	if s.Jumps != 1 || s.Deaths != 1 || s.ChunksGenerated != 2 {
+		t.Errorf("jumps %d deaths %d chunks %d, want 1 1 2", s.Jumps, s.Deaths, s.ChunksGenerated)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats", "player.json")
+
+	s := New()
+	if err := s.Load(path); err != nil {
+		t.Fatalf("Load of a missing file: %v", err)
+	}
+	s.BlocksMined["stone"] = 7
+	s.DistanceWalked = 12.5
+	s.Jumps = 3
+	s.AddPlayTime(90)
+	if err := s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	// Saving again replaces the file rather than appending
+	if err := s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("stats directory holds %d files, want 1", len(entries))
+	}
+
+	got := New()
+	if err := got.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if got.BlocksMined["stone"] != 7 || got.DistanceWalked != 12.5 || got.Jumps != 3 || got.PlayTime != 90 {
+		t.Errorf("loaded %+v", got)
+	}
+	if got.BlocksPlaced == nil {
+		t.Error("Load left BlocksPlaced nil")
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := New().Load(path); err == nil {
+		t.Error("Load of a truncated file succeeded")
+	}
+}
@@ -0,0 +1,139 @@
+package text
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"os"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	atlasSize   = 512 // Width and height of the atlas image in pixels
+	glyphMargin = 1   // Empty pixels around each glyph to avoid bleeding
+)
+
+// Glyph locates a baked glyph in the atlas. Offsets are in pixels relative
+// to the pen position on the baseline, with y pointing down as in the font.
+type Glyph struct {
+	X, Y, W, H int     // Rectangle in the atlas image
+	OffX, OffY int     // Top-left of the glyph relative to the pen
+	Advance    float32 // Pen movement after the glyph
+}
+
+// Atlas bakes glyphs from a font face into a single alpha image. Glyphs are
+// baked once on first use; Dirty reports whether the image changed since
+// the last upload.
+type Atlas struct {
+	Image      *image.Alpha
+	Glyphs     map[rune]Glyph
+	LineHeight float32 // Distance between baselines
+	Ascent     float32 // Baseline distance below the top of a line
+	Dirty      bool
+
+	face    font.Face
+	penX    int // Next free position in the current shelf
+	penY    int
+	shelfH  int // Height of the tallest glyph in the current shelf
+	missing Glyph
+}
+
+// LoadFont parses a TTF file into a face at the given point size and DPI.
+func LoadFont(path string, size, dpi float64) (font.Face, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read font: %w", err)
+	}
+	fnt, err := truetype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font: %w", err)
+	}
+	return truetype.NewFace(fnt, &truetype.Options{Size: size, DPI: dpi, Hinting: font.HintingFull}), nil
+}
+
+// NewAtlas creates an atlas for a face with printable ASCII already baked.
+func NewAtlas(face font.Face) *Atlas {
+	m := face.Metrics()
+	a := &Atlas{
+		Image:      image.NewAlpha(image.Rect(0, 0, atlasSize, atlasSize)),
+		Glyphs:     make(map[rune]Glyph),
+		LineHeight: fixedToFloat(m.Height),
+		Ascent:     fixedToFloat(m.Ascent),
+		face:       face,
+		penX:       glyphMargin,
+		penY:       glyphMargin,
+	}
+	a.missing, _ = a.bake('?')
+	for r := rune(' '); r <= '~'; r++ {
+		a.Glyph(r)
+	}
+	return a
+}
+
+// Glyph returns the glyph for r, baking it if needed. Runes the font lacks,
+// or that no longer fit in the atlas, use the glyph for '?'.
+func (a *Atlas) Glyph(r rune) Glyph {
+	if g, ok := a.Glyphs[r]; ok {
+		return g
+	}
+	g, ok := a.bake(r)
+	if !ok {
+		g = a.missing
+	}
+	a.Glyphs[r] = g
+	return g
+}
+
+// Kern returns the kerning adjustment between two runes in pixels.
+func (a *Atlas) Kern(prev, r rune) float32 {
+	return fixedToFloat(a.face.Kern(prev, r))
+}
+
+// Close releases the font face.
+func (a *Atlas) Close() error {
+	return a.face.Close()
+}
+
+// bake rasterizes r into the next free spot of the atlas.
+func (a *Atlas) bake(r rune) (Glyph, bool) {
+	bounds, advance, ok := a.face.GlyphBounds(r)
+	if !ok {
+		return Glyph{}, false
+	}
+	g := Glyph{
+		OffX:    bounds.Min.X.Floor(),
+		OffY:    bounds.Min.Y.Floor(),
+		Advance: fixedToFloat(advance),
+	}
+	g.W = bounds.Max.X.Ceil() - g.OffX
+	g.H = bounds.Max.Y.Ceil() - g.OffY
+	if g.W <= 0 || g.H <= 0 {
+		g.W, g.H = 0, 0 // Whitespace only advances the pen
+		return g, true
+	}
+	if a.penX+g.W+glyphMargin > atlasSize {
+		a.penX = glyphMargin
+		a.penY += a.shelfH + glyphMargin
+		a.shelfH = 0
+	}
+	if a.penY+g.H+glyphMargin > atlasSize {
+		return Glyph{}, false
+	}
+	g.X, g.Y = a.penX, a.penY
+	dr, mask, maskp, _, ok := a.face.Glyph(fixed.P(g.X-g.OffX, g.Y-g.OffY), r)
+	if !ok {
+		return Glyph{}, false
+	}
+	draw.DrawMask(a.Image, dr, image.White, image.Point{}, mask, maskp, draw.Over)
+	a.penX += g.W + glyphMargin
+	a.shelfH = max(a.shelfH, g.H)
+	a.Dirty = true
+	return g, true
+}
+
+func fixedToFloat(v fixed.Int26_6) float32 {
+	return float32(v) / 64
+}
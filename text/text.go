@@ -0,0 +1,219 @@
+// Package text draws screen-space text from a baked glyph atlas, batching
+// each string into a single draw call.
+package text
+
+import (
+	"strings"
+
+	"something/metrics"
+	"something/shader"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Align is the horizontal alignment of each line relative to the x position.
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// Style controls how a string is drawn.
+type Style struct {
+	Color  mgl32.Vec4
+	Shadow bool  // Draws a dark copy offset down and right behind the text
+	Align  Align // Right-aligned text ends at x
+	Scale  float32
+}
+
+// White is the default style: white, left-aligned, unscaled.
+var White = Style{Color: mgl32.Vec4{1, 1, 1, 1}, Scale: 1}
+
+const (
+	shadowOffset = 2   // Pixels, before scaling
+	shadowAlpha  = 0.6 // Shadow opacity relative to the text colour
+	floatsPerVtx = 8   // x, y, u, v, r, g, b, a
+)
+
+// Renderer draws text with an atlas in window pixel coordinates, with the
+// origin at the bottom-left.
+type Renderer struct {
+	Atlas *Atlas
+
+	program    *shader.Program
+	texture    uint32
+	vao, vbo   uint32
+	projection mgl32.Mat4
+	vertices   []float32 // Reused between draws
+}
+
+// NewRenderer loads a font and creates the GL resources for drawing it.
+func NewRenderer(fontPath string, size, dpi float64, width, height int) (*Renderer, error) {
+	face, err := LoadFont(fontPath, size, dpi)
+	if err != nil {
+		return nil, err
+	}
+	program, err := shader.Load("text")
+	if err != nil {
+		face.Close()
+		return nil, err
+	}
+	r := &Renderer{Atlas: NewAtlas(face), program: program}
+	r.SetViewport(width, height)
+
+	gl.GenTextures(1, &r.texture)
+	gl.BindTexture(gl.TEXTURE_2D, r.texture)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+
+	gl.GenVertexArrays(1, &r.vao)
+	gl.GenBuffers(1, &r.vbo)
+	gl.BindVertexArray(r.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.vbo)
+	gl.VertexAttribPointer(0, 2, gl.FLOAT, false, floatsPerVtx*4, gl.PtrOffset(0))
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(1, 2, gl.FLOAT, false, floatsPerVtx*4, gl.PtrOffset(2*4))
+	gl.EnableVertexAttribArray(1)
+	gl.VertexAttribPointer(2, 4, gl.FLOAT, false, floatsPerVtx*4, gl.PtrOffset(4*4))
+	gl.EnableVertexAttribArray(2)
+	gl.BindVertexArray(0)
+	return r, nil
+}
+
+// SetViewport updates the projection after the framebuffer is resized.
+func (r *Renderer) SetViewport(width, height int) {
+	r.projection = mgl32.Ortho(0, float32(width), 0, float32(height), -1, 1)
+}
+
+// LineHeight returns the distance between baselines at a scale.
+func (r *Renderer) LineHeight(scale float32) float32 {
+	return r.Atlas.LineHeight * scaleOr1(scale)
+}
+
+// Measure returns the width of the widest line and the total height of s.
+func (r *Renderer) Measure(s string, scale float32) (width, height float32) {
+	lines := strings.Split(s, "\n")
+	for _, line := range lines {
+		width = max(width, lineWidth(r.Atlas, line)*scaleOr1(scale))
+	}
+	return width, float32(len(lines)) * r.LineHeight(scale)
+}
+
+// Draw renders s with the first line's baseline at y. Each further line is
+// one line height lower.
+func (r *Renderer) Draw(s string, x, y float32, style Style) {
+	r.vertices = Layout(r.Atlas, r.vertices[:0], s, x, y, style)
+	if len(r.vertices) == 0 {
+		return
+	}
+	r.program.Use()
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, r.texture)
+	if r.Atlas.Dirty {
+		gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+		gl.TexImage2D(gl.TEXTURE_2D, 0, gl.R8, atlasSize, atlasSize, 0, gl.RED, gl.UNSIGNED_BYTE, gl.Ptr(r.Atlas.Image.Pix))
+		r.Atlas.Dirty = false
+	}
+	r.program.SetMat4("projection", r.projection)
+
+	depthTest := gl.IsEnabled(gl.DEPTH_TEST)
+	gl.Disable(gl.DEPTH_TEST)
+	gl.BindVertexArray(r.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, len(r.vertices)*4, gl.Ptr(r.vertices), gl.STREAM_DRAW)
+	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(r.vertices)/floatsPerVtx))
+	metrics.Add(metrics.DrawCalls, 1)
+	gl.BindVertexArray(0)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+	if depthTest {
+		gl.Enable(gl.DEPTH_TEST)
+	}
+}
+
+// Cleanup releases the renderer's GL resources and font.
+func (r *Renderer) Cleanup() {
+	r.program.Delete()
+	gl.DeleteTextures(1, &r.texture)
+	gl.DeleteVertexArrays(1, &r.vao)
+	gl.DeleteBuffers(1, &r.vbo)
+	r.Atlas.Close()
+}
+
+// Layout appends the triangles for s to dst: shadow quads first, so the
+// text is drawn over them in the same batch.
+func Layout(a *Atlas, dst []float32, s string, x, y float32, style Style) []float32 {
+	if style.Shadow {
+		shadow := style
+		shadow.Color = mgl32.Vec4{0, 0, 0, style.Color.W() * shadowAlpha}
+		off := shadowOffset * scaleOr1(style.Scale)
+		dst = layoutLines(a, dst, s, x+off, y-off, shadow)
+	}
+	return layoutLines(a, dst, s, x, y, style)
+}
+
+func layoutLines(a *Atlas, dst []float32, s string, x, y float32, style Style) []float32 {
+	scale := scaleOr1(style.Scale)
+	c := style.Color
+	for _, line := range strings.Split(s, "\n") {
+		penX := x
+		switch style.Align {
+		case AlignCenter:
+			penX -= lineWidth(a, line) * scale / 2
+		case AlignRight:
+			penX -= lineWidth(a, line) * scale
+		}
+		prev := rune(-1)
+		for _, ch := range line {
+			if prev >= 0 {
+				penX += a.Kern(prev, ch) * scale
+			}
+			g := a.Glyph(ch)
+			if g.W > 0 {
+				x0 := penX + float32(g.OffX)*scale
+				y0 := y - float32(g.OffY)*scale // Top edge; OffY is negative above the baseline
+				x1, y1 := x0+float32(g.W)*scale, y0-float32(g.H)*scale
+				u0, v0 := float32(g.X)/atlasSize, float32(g.Y)/atlasSize
+				u1, v1 := float32(g.X+g.W)/atlasSize, float32(g.Y+g.H)/atlasSize
+				dst = append(dst,
+					x0, y1, u0, v1, c[0], c[1], c[2], c[3],
+					x1, y1, u1, v1, c[0], c[1], c[2], c[3],
+					x1, y0, u1, v0, c[0], c[1], c[2], c[3],
+					x0, y1, u0, v1, c[0], c[1], c[2], c[3],
+					x1, y0, u1, v0, c[0], c[1], c[2], c[3],
+					x0, y0, u0, v0, c[0], c[1], c[2], c[3],
+				)
+			}
+			penX += g.Advance * scale
+			prev = ch
+		}
+		y -= a.LineHeight * scale
+	}
+	return dst
+}
+
+// lineWidth is the unscaled advance width of a single line.
+func lineWidth(a *Atlas, line string) float32 {
+	var w float32
+	prev := rune(-1)
+	for _, ch := range line {
+		if prev >= 0 {
+			w += a.Kern(prev, ch)
+		}
+		w += a.Glyph(ch).Advance
+		prev = ch
+	}
+	return w
+}
+
+func scaleOr1(scale float32) float32 {
+	if scale == 0 {
+		return 1
+	}
+	return scale
+}
@@ -0,0 +1,117 @@
+package text
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Face7x13 glyphs are 6x13 boxes, 11 pixels above the baseline, with a
+// 7 pixel advance and 13 pixel lines.
+const (
+	glyphW  = 6
+	advance = 7
+	ascent  = 11
+	descent = 2
+	lineH   = 13
+)
+
+// kernedFace adds kerning pairs to a face.
+type kernedFace struct {
+	font.Face
+	pairs map[[2]rune]float32
+}
+
+func (f kernedFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	return fixed.Int26_6(f.pairs[[2]rune{r0, r1}] * 64)
+}
+
+// quad is one glyph's screen rectangle.
+type quad struct{ left, top, right, bottom float32 }
+
+// quads extracts the glyph rectangles from Layout's vertices.
+func quads(v []float32) []quad {
+	var out []quad
+	for i := 0; i+6*floatsPerVtx <= len(v); i += 6 * floatsPerVtx {
+		// The first vertex is bottom-left and the third top-right
+		out = append(out, quad{v[i], v[i+2*floatsPerVtx+1], v[i+2*floatsPerVtx], v[i+1]})
+	}
+	return out
+}
+
+// glyphAt is the rectangle of a glyph drawn with its pen at x on baseline y.
+func glyphAt(x, y, scale float32) quad {
+	return quad{x, y + ascent*scale, x + glyphW*scale, y - descent*scale}
+}
+
+func TestLayout(t *testing.T) {
+	a := NewAtlas(basicfont.Face7x13)
+	tests := []struct {
+		name  string
+		s     string
+		style Style
+		want  []quad
+	}{
+		{"left", "AB", White, []quad{glyphAt(100, 50, 1), glyphAt(100+advance, 50, 1)}},
+		{"lines", "A\nBC", White, []quad{
+			glyphAt(100, 50, 1),
+			glyphAt(100, 50-lineH, 1), glyphAt(100+advance, 50-lineH, 1),
+		}},
+		{"right aligned lines end at x", "A\nBC", Style{Align: AlignRight}, []quad{
+			glyphAt(100-advance, 50, 1),
+			glyphAt(100-2*advance, 50-lineH, 1), glyphAt(100-advance, 50-lineH, 1),
+		}},
+		{"centred", "BC", Style{Align: AlignCenter}, []quad{glyphAt(100-advance, 50, 1), glyphAt(100, 50, 1)}},
+		{"scaled", "A\nB", Style{Scale: 2}, []quad{glyphAt(100, 50, 2), glyphAt(100, 50-2*lineH, 2)}},
+		{"empty line", "A\n\nB", White, []quad{glyphAt(100, 50, 1), glyphAt(100, 50-2*lineH, 1)}},
+		{"empty", "", White, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := quads(Layout(a, nil, tt.s, 100, 50, tt.style))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v\nwant %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLayoutShadow(t *testing.T) {
+	a := NewAtlas(basicfont.Face7x13)
+	style := Style{Color: mgl32.Vec4{1, 0.5, 0.25, 0.5}, Shadow: true, Scale: 2}
+	v := Layout(a, nil, "A", 100, 50, style)
+	got := quads(v)
+	off := float32(shadowOffset * 2)
+	want := []quad{glyphAt(100+off, 50-off, 2), glyphAt(100, 50, 2)}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %v, want the shadow then the text %v", got, want)
+	}
+	if c := v[4:8]; !slices.Equal(c, []float32{0, 0, 0, 0.5 * shadowAlpha}) {
+		t.Errorf("shadow colour %v", c)
+	}
+	if c := v[6*floatsPerVtx+4 : 6*floatsPerVtx+8]; !slices.Equal(c, []float32{1, 0.5, 0.25, 0.5}) {
+		t.Errorf("text colour %v", c)
+	}
+}
+
+func TestKerning(t *testing.T) {
+	a := NewAtlas(kernedFace{basicfont.Face7x13, map[[2]rune]float32{{'A', 'V'}: -2}})
+	if w := lineWidth(a, "AVA"); w != 3*advance-2 {
+		t.Errorf("lineWidth(AVA) = %v, want %v", w, 3*advance-2)
+	}
+	got := quads(Layout(a, nil, "AVA", 100, 50, Style{Align: AlignRight}))
+	left := float32(100 - (3*advance - 2))
+	want := []quad{glyphAt(left, 50, 1), glyphAt(left+advance-2, 50, 1), glyphAt(left+2*advance-2, 50, 1)}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v\nwant %v", got, want)
+	}
+
+	r := &Renderer{Atlas: a}
+	if w, h := r.Measure("AVA\nA", 2); w != 2*(3*advance-2) || h != 2*2*lineH {
+		t.Errorf("Measure = %v x %v, want %v x %v", w, h, 2*(3*advance-2), 2*2*lineH)
+	}
+}
@@ -0,0 +1,185 @@
+package update
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Suffixes of the files kept next to the executable.
+const (
+	stagedSuffix = ".new" // Verified download waiting to be swapped in
+	backupSuffix = ".old" // Previous build, restored by Rollback
+)
+
+// selfTestTimeout bounds the default self test.
+const selfTestTimeout = 10 * time.Second
+
+// Updater replaces an executable with the newest release from a Client.
+type Updater struct {
+	Client  *Client
+	Current string // Version of the running build
+	Exe     string // Executable to replace; empty means os.Executable
+	// SelfTest checks that a newly swapped-in build starts. nil runs it
+	// with -version and expects it to print version.
+	SelfTest func(ctx context.Context, exe, version string) error
+}
+
+func (u *Updater) exe() (string, error) {
+	if u.Exe != "" {
+		return u.Exe, nil
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to find executable: %w", err)
+	}
+	return exe, nil
+}
+
+// Update installs the newest release if it is newer than Current and
+// returns its version, or "" when already up to date. A version that
+// CheckStartup rolled back is skipped until a newer one is released. If
+// the new build fails its self test the previous one is restored and an
+// error returned.
+// The running process keeps running the old build. The new build must call
+// CheckStartup and ConfirmStartup, or its next launch rolls it back.
+func (u *Updater) Update(ctx context.Context) (string, error) {
+	exe, err := u.exe()
+	if err != nil {
+		return "", err
+	}
+	rel, build, err := u.Client.Latest(ctx)
+	if errors.Is(err, ErrNoRelease) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if Compare(rel.Version, u.Current) <= 0 {
+		return "", nil
+	}
+	bad, err := rejected(exe)
+	if err != nil {
+		return "", err
+	}
+	if bad != "" && Compare(rel.Version, bad) <= 0 {
+		return "", nil
+	}
+	staged := exe + stagedSuffix
+	if err := u.Client.Download(ctx, build, staged); err != nil {
+		return "", err
+	}
+	if err := u.apply(ctx, exe, staged, rel.Version); err != nil {
+		return "", err
+	}
+	return rel.Version, nil
+}
+
+// apply swaps staged in for exe, self tests it and marks it as pending a
+// healthy startup, rolling back on failure.
+func (u *Updater) apply(ctx context.Context, exe, staged, version string) error {
+	if err := swap(exe, staged, exe+backupSuffix); err != nil {
+		os.Remove(staged)
+		return err
+	}
+	test := u.SelfTest
+	if test == nil {
+		test = versionTest
+	}
+	err := test(ctx, exe, version)
+	if err != nil {
+		err = fmt.Errorf("self test failed: %w", err)
+	} else {
+		if err = writePending(exe, pending{Version: version}); err == nil {
+			return nil
+		}
+	}
+	if rbErr := Rollback(exe); rbErr != nil {
+		return fmt.Errorf("update to %s failed (%v) and rollback failed: %w", version, err, rbErr)
+	}
+	return fmt.Errorf("update to %s failed, rolled back: %w", version, err)
+}
+
+// swap replaces exe with staged, keeping exe as backup. On Unix exe is
+// replaced by a single rename, so it always exists. Windows cannot replace
+// a running executable, so there it is moved aside first.
+func swap(exe, staged, backup string) error {
+	if err := os.Remove(backup); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove old backup: %w", err)
+	}
+	if runtime.GOOS == "windows" {
+		if err := os.Rename(exe, backup); err != nil {
+			return fmt.Errorf("failed to back up executable: %w", err)
+		}
+		if err := os.Rename(staged, exe); err != nil {
+			os.Rename(backup, exe)
+			return fmt.Errorf("failed to install update: %w", err)
+		}
+		return nil
+	}
+	if err := os.Link(exe, backup); err != nil {
+		return fmt.Errorf("failed to back up executable: %w", err)
+	}
+	if err := os.Rename(staged, exe); err != nil {
+		os.Remove(backup)
+		return fmt.Errorf("failed to install update: %w", err)
+	}
+	return nil
+}
+
+// Rollback restores the build that the last update replaced.
+func Rollback(exe string) error {
+	backup := exe + backupSuffix
+	if _, err := os.Stat(backup); err != nil {
+		return fmt.Errorf("no previous build to roll back to: %w", err)
+	}
+	if runtime.GOOS == "windows" {
+		// The executable may be running, so move it aside rather than over it
+		aside := exe + stagedSuffix
+		os.Remove(aside)
+		if err := os.Rename(exe, aside); err != nil {
+			return fmt.Errorf("failed to move executable aside: %w", err)
+		}
+	}
+	if err := os.Rename(backup, exe); err != nil {
+		return fmt.Errorf("failed to restore previous build: %w", err)
+	}
+	return clearPending(exe)
+}
+
+// versionTest runs exe -version and checks that it prints version.
+func versionTest(ctx context.Context, exe, version string) error {
+	ctx, cancel := context.WithTimeout(ctx, selfTestTimeout)
+	defer cancel()
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, exe, "-version")
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(out.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	if got := strings.TrimSpace(out.String()); got != version {
+		return fmt.Errorf("build reports version %q, want %q", got, version)
+	}
+	return nil
+}
+
+// Restart starts exe with args, sharing this process's standard streams.
+// The caller should exit once it returns.
+func Restart(exe string, args []string) error {
+	cmd := exec.Command(exe, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to restart into update: %w", err)
+	}
+	return cmd.Process.Release()
+}
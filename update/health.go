@@ -0,0 +1,99 @@
+package update
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Suffixes of the files that track update health.
+const (
+	pendingSuffix  = ".pending"  // Update whose build has not yet confirmed a healthy startup
+	rejectedSuffix = ".rejected" // Version rolled back after a failed startup
+)
+
+// pending is the content of the pending file.
+type pending struct {
+	Version string `json:"version"`
+	Started bool   `json:"started"` // A launch of Version has begun
+}
+
+func readPending(exe string) (pending, bool, error) {
+	var p pending
+	data, err := os.ReadFile(exe + pendingSuffix)
+	if os.IsNotExist(err) {
+		return p, false, nil
+	}
+	if err != nil {
+		return p, false, fmt.Errorf("failed to read update state: %w", err)
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return p, false, fmt.Errorf("failed to parse update state: %w", err)
+	}
+	return p, true, nil
+}
+
+func writePending(exe string, p pending) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(exe+pendingSuffix, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write update state: %w", err)
+	}
+	return nil
+}
+
+func clearPending(exe string) error {
+	if err := os.Remove(exe + pendingSuffix); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear update state: %w", err)
+	}
+	return nil
+}
+
+// CheckStartup is called early by every launch of exe. The first launch of
+// a freshly installed version is recorded; if a later launch finds that the
+// earlier one never called ConfirmStartup, the build is assumed broken and
+// the previous one restored. It reports whether it rolled back, in which
+// case the caller should restart into the restored build.
+func CheckStartup(exe, version string) (bool, error) {
+	p, ok, err := readPending(exe)
+	if err != nil || !ok {
+		return false, err
+	}
+	switch {
+	case p.Version != version:
+		// Not the build the update installed, e.g. after a manual rollback
+		return false, clearPending(exe)
+	case !p.Started:
+		p.Started = true
+		return false, writePending(exe, p)
+	}
+	if err := Rollback(exe); err != nil {
+		return false, fmt.Errorf("update to %s never started successfully and rollback failed: %w", version, err)
+	}
+	// Keep Update from reinstalling the same build on the next check
+	if err := os.WriteFile(exe+rejectedSuffix, []byte(version), 0o644); err != nil {
+		return true, fmt.Errorf("failed to record rejected update: %w", err)
+	}
+	return true, nil
+}
+
+// rejected returns the version last rolled back by CheckStartup, or "".
+func rejected(exe string) (string, error) {
+	data, err := os.ReadFile(exe + rejectedSuffix)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read rejected update: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// ConfirmStartup records that the running build started successfully, so
+// later launches keep it.
+func ConfirmStartup(exe string) error {
+	return clearPending(exe)
+}
@@ -0,0 +1,211 @@
+// Package update checks the web server's release API for a newer build of
+// the game, downloads it with checksum verification and swaps it in place
+// of the running executable, keeping the previous build for rollback.
+package update
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ErrNoRelease means the server has no release for the channel and platform.
+var ErrNoRelease = errors.New("no release available")
+
+// Release is a version as listed by the server's /api/releases/latest.
+type Release struct {
+	Version   string  `json:"version"`
+	Channel   string  `json:"channel"`
+	Changelog string  `json:"changelog"`
+	Builds    []Build `json:"builds"`
+}
+
+// Build is one platform's file of a release.
+type Build struct {
+	Platform string `json:"platform"`
+	File     string `json:"file"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256"`
+	URL      string `json:"url"` // Relative to the server
+}
+
+// Platform is the running build's platform in the server's naming.
+func Platform() string {
+	return runtime.GOOS + "-" + runtime.GOARCH
+}
+
+// Client talks to the web server's release API.
+type Client struct {
+	BaseURL  string       // e.g. http://localhost:8080
+	Channel  string       // Empty means the server's default channel
+	Platform string       // Empty means Platform()
+	HTTP     *http.Client // nil means http.DefaultClient
+}
+
+func (c *Client) http() *http.Client {
+	if c.HTTP == nil {
+		return http.DefaultClient
+	}
+	return c.HTTP
+}
+
+func (c *Client) resolve(ref string) (string, error) {
+	base, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid update server %q: %w", c.BaseURL, err)
+	}
+	u, err := base.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid release URL %q: %w", ref, err)
+	}
+	return u.String(), nil
+}
+
+// Latest returns the newest release on the channel and its build for the
+// platform.
+func (c *Client) Latest(ctx context.Context) (Release, Build, error) {
+	platform := c.Platform
+	if platform == "" {
+		platform = Platform()
+	}
+	query := url.Values{"platform": {platform}}
+	if c.Channel != "" {
+		query.Set("channel", c.Channel)
+	}
+	endpoint, err := c.resolve("/api/releases/latest?" + query.Encode())
+	if err != nil {
+		return Release{}, Build{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Release{}, Build{}, err
+	}
+	resp, err := c.http().Do(req)
+	if err != nil {
+		return Release{}, Build{}, fmt.Errorf("failed to fetch release manifest: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return Release{}, Build{}, ErrNoRelease
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Release{}, Build{}, fmt.Errorf("failed to fetch release manifest: %s", resp.Status)
+	}
+	var rel Release
+	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		return Release{}, Build{}, fmt.Errorf("failed to parse release manifest: %w", err)
+	}
+	for _, b := range rel.Builds {
+		if b.Platform == platform {
+			return rel, b, nil
+		}
+	}
+	return rel, Build{}, ErrNoRelease
+}
+
+// Download fetches a build to dest, verifying its size and SHA-256. The
+// file is written under a temporary name in dest's directory and renamed
+// into place only once verified, so dest is never a partial download.
+func (c *Client) Download(ctx context.Context, b Build, dest string) error {
+	endpoint, err := c.resolve(b.URL)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", b.File, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: %s", b.File, resp.Status)
+	}
+	f, err := os.CreateTemp(filepath.Dir(dest), filepath.Base(dest)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create download file: %w", err)
+	}
+	defer os.Remove(f.Name()) // No-op once renamed
+	h := sha256.New()
+	// Read at most one byte past the expected size, enough to detect an
+	// oversized body without writing all of it
+	n, err := io.Copy(io.MultiWriter(f, h), io.LimitReader(resp.Body, b.Size+1))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", b.File, err)
+	}
+	if n != b.Size {
+		return fmt.Errorf("download of %s is %d bytes, want %d", b.File, n, b.Size)
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(sum, b.SHA256) {
+		return fmt.Errorf("checksum mismatch for %s: got %s, want %s", b.File, sum, b.SHA256)
+	}
+	if err := os.Chmod(f.Name(), 0o755); err != nil {
+		return fmt.Errorf("failed to make download executable: %w", err)
+	}
+	if err := os.Rename(f.Name(), dest); err != nil {
+		return fmt.Errorf("failed to stage download: %w", err)
+	}
+	return nil
+}
+
+// Compare orders versions such as 1.10.0 after 1.9.2, ignoring a leading
+// v; a pre-release (1.2.0-beta.1) sorts before its release. It matches the
+// web server's ordering.
+func Compare(a, b string) int {
+	a, preA, _ := strings.Cut(strings.TrimPrefix(a, "v"), "-")
+	b, preB, _ := strings.Cut(strings.TrimPrefix(b, "v"), "-")
+	if c := compareDotted(a, b); c != 0 {
+		return c
+	}
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+	return compareDotted(preA, preB)
+}
+
+// compareDotted compares dot-separated parts, numerically where both are
+// numbers.
+func compareDotted(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		if i >= len(pa) {
+			return -1
+		}
+		if i >= len(pb) {
+			return 1
+		}
+		na, errA := strconv.Atoi(pa[i])
+		nb, errB := strconv.Atoi(pb[i])
+		switch {
+		case errA == nil && errB == nil && na != nb:
+			if na < nb {
+				return -1
+			}
+			return 1
+		case (errA != nil || errB != nil) && pa[i] != pb[i]:
+			return strings.Compare(pa[i], pb[i])
+		}
+	}
+	return 0
+}
@@ -0,0 +1,284 @@
+package update
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	oldBuild = "old build"
+	newBuild = "new build"
+)
+
+// releaseServer stands in for the web server, offering version 1.1.0 whose
+// build is newBuild. manifest may alter the advertised build.
+func releaseServer(t *testing.T, manifest func(b *Build)) *httptest.Server {
+	t.Helper()
+	sum := sha256.Sum256([]byte(newBuild))
+	b := Build{
+		Platform: "test-arch",
+		File:     "main-test-arch",
+		Size:     int64(len(newBuild)),
+		SHA256:   hex.EncodeToString(sum[:]),
+		URL:      "/releases/1.1.0/main-test-arch",
+	}
+	if manifest != nil {
+		manifest(&b)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("platform") != "test-arch" {
+			http.Error(w, "No build", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(Release{Version: "1.1.0", Channel: "stable", Builds: []Build{b}})
+	})
+	mux.HandleFunc("/releases/1.1.0/main-test-arch", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(newBuild))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// installed writes oldBuild as the executable in a temporary directory.
+func installed(t *testing.T) string {
+	t.Helper()
+	exe := filepath.Join(t.TempDir(), "main")
+	if err := os.WriteFile(exe, []byte(oldBuild), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return exe
+}
+
+func newUpdater(srv *httptest.Server, exe, current string, selfTest func(context.Context, string, string) error) *Updater {
+	if selfTest == nil {
+		selfTest = func(ctx context.Context, exe, version string) error { return nil }
+	}
+	return &Updater{
+		Client:   &Client{BaseURL: srv.URL, Platform: "test-arch", HTTP: srv.Client()},
+		Current:  current,
+		Exe:      exe,
+		SelfTest: selfTest,
+	}
+}
+
+func assertFile(t *testing.T, path, want string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", filepath.Base(path), err)
+	}
+	if string(data) != want {
+		t.Errorf("%s holds %q, want %q", filepath.Base(path), data, want)
+	}
+}
+
+func assertMissing(t *testing.T, path string) {
+	t.Helper()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("%s exists, want it removed", filepath.Base(path))
+	}
+}
+
+func TestUpToDate(t *testing.T) {
+	srv := releaseServer(t, nil)
+	for _, current := range []string{"1.1.0", "1.2.0"} {
+		exe := installed(t)
+		got, err := newUpdater(srv, exe, current, nil).Update(context.Background())
+		if err != nil || got != "" {
+			t.Errorf("Update from %s = %q, %v; want no update", current, got, err)
+		}
+		assertFile(t, exe, oldBuild)
+		assertMissing(t, exe+backupSuffix)
+	}
+
+	exe := installed(t)
+	u := newUpdater(srv, exe, "1.0.0", nil)
+	u.Client.Platform = "other-arch"
+	if got, err := u.Update(context.Background()); err != nil || got != "" {
+		t.Errorf("Update without a build for the platform = %q, %v; want no update", got, err)
+	}
+}
+
+func TestDownloadRejectsBadBuilds(t *testing.T) {
+	tests := []struct {
+		name    string
+		alter   func(b *Build)
+		wantErr string
+	}{
+		{"size mismatch", func(b *Build) { b.Size++ }, "bytes, want"},
+		{"oversized body", func(b *Build) { b.Size = 3 }, "is 4 bytes, want 3"},
+		{"checksum mismatch", func(b *Build) { b.SHA256 = strings.Repeat("0", 64) }, "checksum mismatch"},
+		{"missing file", func(b *Build) { b.URL = "/releases/1.1.0/gone" }, "404"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := releaseServer(t, tt.alter)
+			exe := installed(t)
+			_, err := newUpdater(srv, exe, "1.0.0", nil).Update(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Update error = %v, want one containing %q", err, tt.wantErr)
+			}
+			assertFile(t, exe, oldBuild)
+			assertMissing(t, exe+stagedSuffix)
+			assertMissing(t, exe+backupSuffix)
+			entries, _ := os.ReadDir(filepath.Dir(exe))
+			if len(entries) != 1 {
+				t.Errorf("download left %d files behind", len(entries)-1)
+			}
+		})
+	}
+}
+
+func TestUpdateSwapsBuild(t *testing.T) {
+	srv := releaseServer(t, nil)
+	exe := installed(t)
+	var tested string
+	got, err := newUpdater(srv, exe, "1.0.0", func(ctx context.Context, path, version string) error {
+		tested = version
+		assertFile(t, path, newBuild)
+		return nil
+	}).Update(context.Background())
+	if err != nil || got != "1.1.0" {
+		t.Fatalf("Update = %q, %v; want 1.1.0", got, err)
+	}
+	if tested != "1.1.0" {
+		t.Errorf("self test ran for %q, want 1.1.0", tested)
+	}
+	assertFile(t, exe, newBuild)
+	assertFile(t, exe+backupSuffix, oldBuild)
+	assertMissing(t, exe+stagedSuffix)
+	if info, err := os.Stat(exe); err != nil || info.Mode().Perm()&0o100 == 0 {
+		t.Errorf("new build is not executable: %v", err)
+	}
+
+	if err := Rollback(exe); err != nil {
+		t.Fatal(err)
+	}
+	assertFile(t, exe, oldBuild)
+	assertMissing(t, exe+backupSuffix)
+	assertMissing(t, exe+pendingSuffix)
+}
+
+func TestFailedSelfTestRestoresBuild(t *testing.T) {
+	srv := releaseServer(t, nil)
+	exe := installed(t)
+	broken := errors.New("crashed on start")
+	_, err := newUpdater(srv, exe, "1.0.0", func(ctx context.Context, path, version string) error {
+		return broken
+	}).Update(context.Background())
+	if !errors.Is(err, broken) {
+		t.Fatalf("Update error = %v, want the self test failure", err)
+	}
+	assertFile(t, exe, oldBuild)
+	assertMissing(t, exe+backupSuffix)
+	assertMissing(t, exe+pendingSuffix)
+}
+
+func TestStartupRollsBackUnconfirmedBuild(t *testing.T) {
+	srv := releaseServer(t, nil)
+
+	t.Run("confirmed", func(t *testing.T) {
+		exe := installed(t)
+		if _, err := newUpdater(srv, exe, "1.0.0", nil).Update(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if rolledBack, err := CheckStartup(exe, "1.1.0"); err != nil || rolledBack {
+			t.Fatalf("first launch CheckStartup = %v, %v", rolledBack, err)
+		}
+		if err := ConfirmStartup(exe); err != nil {
+			t.Fatal(err)
+		}
+		if rolledBack, err := CheckStartup(exe, "1.1.0"); err != nil || rolledBack {
+			t.Fatalf("launch after confirming CheckStartup = %v, %v", rolledBack, err)
+		}
+		assertFile(t, exe, newBuild)
+	})
+
+	t.Run("never confirmed", func(t *testing.T) {
+		exe := installed(t)
+		if _, err := newUpdater(srv, exe, "1.0.0", nil).Update(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if rolledBack, err := CheckStartup(exe, "1.1.0"); err != nil || rolledBack {
+			t.Fatalf("first launch CheckStartup = %v, %v", rolledBack, err)
+		}
+		// The first launch crashes before ConfirmStartup
+		rolledBack, err := CheckStartup(exe, "1.1.0")
+		if err != nil || !rolledBack {
+			t.Fatalf("second launch CheckStartup = %v, %v; want a rollback", rolledBack, err)
+		}
+		assertFile(t, exe, oldBuild)
+		assertMissing(t, exe+pendingSuffix)
+		if rolledBack, err := CheckStartup(exe, "1.0.0"); err != nil || rolledBack {
+			t.Errorf("restored build CheckStartup = %v, %v", rolledBack, err)
+		}
+
+		// The restored build checks again and must not reinstall 1.1.0
+		got, err := newUpdater(srv, exe, "1.0.0", nil).Update(context.Background())
+		if err != nil || got != "" {
+			t.Fatalf("Update after the rollback = %q, %v; want no update", got, err)
+		}
+		assertFile(t, exe, oldBuild)
+		assertMissing(t, exe+pendingSuffix)
+	})
+}
+
+func TestRejectedVersionSkippedUntilNewer(t *testing.T) {
+	srv := releaseServer(t, nil)
+	tests := []struct {
+		rejected string
+		want     string
+	}{
+		{"1.1.0", ""},
+		{"1.2.0", ""},
+		{"1.0.5", "1.1.0"},
+	}
+	for _, tt := range tests {
+		exe := installed(t)
+		if err := os.WriteFile(exe+rejectedSuffix, []byte(tt.rejected), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := newUpdater(srv, exe, "1.0.0", nil).Update(context.Background())
+		if err != nil || got != tt.want {
+			t.Errorf("Update with %s rejected = %q, %v; want %q", tt.rejected, got, err, tt.want)
+		}
+	}
+}
+
+// compareCase is one entry of testdata/versions.json, which the web
+// server's tests also run against its own copy of the ordering.
+type compareCase struct {
+	A    string `json:"a"`
+	B    string `json:"b"`
+	Want int    `json:"want"`
+}
+
+func TestCompare(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "versions.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests []compareCase
+	if err := json.Unmarshal(data, &tests); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		if got := Compare(tt.A, tt.B); got != tt.Want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.A, tt.B, got, tt.Want)
+		}
+		if got := Compare(tt.B, tt.A); got != -tt.Want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.B, tt.A, got, -tt.Want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// mapRenderer is the worldmap command, built from the repository root with
+// `go build -o bin/worldmap ./cmd/worldmap`. It fills the tile cache when a
+// tile is first requested; tiles can also be rendered ahead of time with
+// its -tiles flag into saves/<world>/map.
+const mapRenderer = "../bin/worldmap"
+
+// mapMaxZoom matches worldmap.MaxZoom; this module does not import the game.
+const mapMaxZoom = 4
+
+// tileRenderTimeout bounds one on-demand render, below the write timeout.
+const tileRenderTimeout = 8 * time.Second
+
+var (
+	mapExtent  = flag.Int("map-extent", 8192, "blocks from the origin in each direction that map tiles are rendered for")
+	mapRenders = flag.Int("map-renders", runtime.NumCPU(), "most map tiles rendered at once")
+)
+
+// errMapBusy is returned when no render slot frees up in time.
+var errMapBusy = errors.New("too many tile renders in progress")
+
+// tileRenders tracks renders in progress so concurrent requests for the
+// same tile wait for one render instead of starting their own. slots
+// limits how many renders run at once; it is sized from -map-renders.
+var tileRenders = struct {
+	sync.Mutex
+	inFlight map[string]*tileRender
+	slots    chan struct{}
+}{inFlight: make(map[string]*tileRender)}
+
+type tileRender struct {
+	done chan struct{}
+	err  error // Set before done is closed
+}
+
+// tileInExtent reports whether tile x, y at a zoom level overlaps the
+// square of -map-extent blocks around the origin.
+func tileInExtent(z, x, y int) bool {
+	size := 256 << (mapMaxZoom - z) // Blocks per tile side
+	lo, hi := floorDiv(-*mapExtent, size), floorDiv(*mapExtent-1, size)
+	return x >= lo && x <= hi && y >= lo && y <= hi
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// handleTile serves /map/<world>/<z>/<x>/<y>.png from the world's tile
+// cache, rendering missing tiles first.
+func handleTile(w http.ResponseWriter, r *http.Request) {
+	world := r.PathValue("world")
+	if !validName(world) {
+		http.Error(w, "Invalid world name", http.StatusBadRequest)
+		return
+	}
+	z, errZ := strconv.Atoi(r.PathValue("z"))
+	x, errX := strconv.Atoi(r.PathValue("x"))
+	y, errY := strconv.Atoi(strings.TrimSuffix(r.PathValue("y"), ".png"))
+	if errZ != nil || errX != nil || errY != nil || z < 0 || z > mapMaxZoom {
+		http.Error(w, "Invalid tile", http.StatusBadRequest)
+		return
+	}
+	if !tileInExtent(z, x, y) {
+		http.Error(w, "Tile outside the map", http.StatusNotFound)
+		return
+	}
+	worldDir := filepath.Join(savesDir, world)
+	if _, err := os.Stat(filepath.Join(worldDir, "world.json")); err != nil {
+		http.Error(w, "World not found", http.StatusNotFound)
+		return
+	}
+	path := filepath.Join(worldDir, "map", strconv.Itoa(z), strconv.Itoa(x), strconv.Itoa(y)+".png")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := renderTile(r.Context(), worldDir, z, x, y); errors.Is(err, errMapBusy) {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "Map is busy, try again", http.StatusServiceUnavailable)
+			return
+		} else if err != nil {
+			log.Printf("%v", err)
+			http.Error(w, "Failed to render tile", http.StatusInternalServerError)
+			return
+		}
+	}
+	// Tiles only show generated terrain, which never changes for a seed
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	http.ServeFile(w, r, path)
+}
+
+// renderTile runs mapRenderer to write one tile into the world's cache.
+// Requests for a tile that is already being rendered share its result.
+func renderTile(ctx context.Context, worldDir string, z, x, y int) error {
+	tile := fmt.Sprintf("%d/%d/%d", z, x, y)
+	key := worldDir + "/" + tile
+	tileRenders.Lock()
+	if tr, ok := tileRenders.inFlight[key]; ok {
+		tileRenders.Unlock()
+		select {
+		case <-tr.done:
+			return tr.err
+		case <-ctx.Done():
+			return errMapBusy
+		}
+	}
+	tr := &tileRender{done: make(chan struct{})}
+	tileRenders.inFlight[key] = tr
+	tileRenders.Unlock()
+	defer func() {
+		tileRenders.Lock()
+		delete(tileRenders.inFlight, key)
+		tileRenders.Unlock()
+		close(tr.done)
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, tileRenderTimeout)
+	defer cancel()
+	select {
+	case tileRenders.slots <- struct{}{}:
+		defer func() { <-tileRenders.slots }()
+	case <-ctx.Done():
+		tr.err = errMapBusy
+		return tr.err
+	}
+	cmd := exec.CommandContext(ctx, mapRenderer, "-world", worldDir, "-tiles", filepath.Join(worldDir, "map"), "-tile", tile)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		tr.err = fmt.Errorf("failed to render tile %s: %w: %s", key, err, strings.TrimSpace(string(out)))
+	}
+	return tr.err
+}
+
+// handlePlayers returns every player's last saved position for
+// ?world=<name>, keyed by player profile.
+func handlePlayers(w http.ResponseWriter, r *http.Request) {
+	serveWorldFiles(w, r, "players")
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// releasesDir holds one directory per version, named after it, e.g.
+//
+//	releases/1.2.0/main-linux-amd64
+//	releases/1.2.0/main-windows-amd64.exe
+//	releases/1.2.0/CHANGELOG.md   (optional)
+//	releases/1.2.0/release.json   (optional: {"channel": "beta", "date": "2026-10-01"})
+//
+// It is a variable so tests can point it at a temporary directory.
+var releasesDir = "../releases"
+
+// defaultChannel is the channel of releases without a release.json, and
+// the one /download and the download page offer.
+const defaultChannel = "stable"
+
+// buildName matches build files: <name>-<os>-<arch>, with .exe on Windows.
+var buildName = regexp.MustCompile(`^.+-([a-z0-9]+)-([a-z0-9]+)(\.exe)?$`)
+
+// Build is one downloadable file of a release.
+type Build struct {
+	Platform string `json:"platform"` // <os>-<arch>, e.g. linux-amd64
+	File     string `json:"file"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256"`
+	URL      string `json:"url"`
+}
+
+// Release is one version and its builds.
+type Release struct {
+	Version   string  `json:"version"`
+	Channel   string  `json:"channel"`
+	Date      string  `json:"date,omitempty"`
+	Changelog string  `json:"changelog,omitempty"`
+	Builds    []Build `json:"builds"`
+}
+
+// Build returns the release's build for a platform.
+func (r Release) Build(platform string) (Build, bool) {
+	for _, b := range r.Builds {
+		if b.Platform == platform {
+			return b, true
+		}
+	}
+	return Build{}, false
+}
+
+// checksums caches build hashes by path, reused while the file's size and
+// modification time are unchanged.
+var checksums = struct {
+	sync.Mutex
+	entries map[string]checksum
+}{entries: make(map[string]checksum)}
+
+type checksum struct {
+	size    int64
+	modTime time.Time
+	sum     string
+}
+
+// fileSHA256 returns the hex SHA-256 of a file, from the cache if possible.
+func fileSHA256(path string, info os.FileInfo) (string, error) {
+	checksums.Lock()
+	c, ok := checksums.entries[path]
+	checksums.Unlock()
+	if ok && c.size == info.Size() && c.modTime.Equal(info.ModTime()) {
+		return c.sum, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	checksums.Lock()
+	checksums.entries[path] = checksum{size: info.Size(), modTime: info.ModTime(), sum: sum}
+	checksums.Unlock()
+	return sum, nil
+}
+
+// loadReleases indexes releasesDir, newest version first. A missing
+// directory means no releases. A release that cannot be read is logged and
+// left out so the others stay available.
+func loadReleases() ([]Release, error) {
+	entries, err := os.ReadDir(releasesDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list releases: %w", err)
+	}
+	var releases []Release
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		r, err := loadRelease(e.Name())
+		if err != nil {
+			log.Printf("skipping release: %v", err)
+			continue
+		}
+		if len(r.Builds) > 0 {
+			releases = append(releases, r)
+		}
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		return compareVersions(releases[i].Version, releases[j].Version) > 0
+	})
+	return releases, nil
+}
+
+func loadRelease(version string) (Release, error) {
+	dir := filepath.Join(releasesDir, version)
+	r := Release{Version: version, Channel: defaultChannel}
+	if data, err := os.ReadFile(filepath.Join(dir, "release.json")); err == nil {
+		if err := json.Unmarshal(data, &r); err != nil {
+			return r, fmt.Errorf("failed to parse %s/release.json: %w", version, err)
+		}
+		r.Version = version // The directory name is authoritative
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "CHANGELOG.md")); err == nil {
+		r.Changelog = strings.TrimSpace(string(data))
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return r, fmt.Errorf("failed to list release %s: %w", version, err)
+	}
+	for _, e := range entries {
+		m := buildName.FindStringSubmatch(e.Name())
+		if m == nil || !e.Type().IsRegular() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return r, fmt.Errorf("failed to stat %s/%s: %w", version, e.Name(), err)
+		}
+		sum, err := fileSHA256(filepath.Join(dir, e.Name()), info)
+		if err != nil {
+			return r, fmt.Errorf("failed to hash %s/%s: %w", version, e.Name(), err)
+		}
+		r.Builds = append(r.Builds, Build{
+			Platform: m[1] + "-" + m[2],
+			File:     e.Name(),
+			Size:     info.Size(),
+			SHA256:   sum,
+			URL:      "/releases/" + version + "/" + e.Name(),
+		})
+	}
+	return r, nil
+}
+
+// compareVersions orders versions such as 1.10.0 after 1.9.2, ignoring a
+// leading v. A pre-release (1.2.0-beta.1) sorts before its release.
+func compareVersions(a, b string) int {
+	a, preA, _ := strings.Cut(strings.TrimPrefix(a, "v"), "-")
+	b, preB, _ := strings.Cut(strings.TrimPrefix(b, "v"), "-")
+	if c := compareDotted(a, b); c != 0 {
+		return c
+	}
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+	return compareDotted(preA, preB)
+}
+
+// compareDotted compares dot-separated parts, numerically where both are
+// numbers.
+func compareDotted(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		if i >= len(pa) {
+			return -1
+		}
+		if i >= len(pb) {
+			return 1
+		}
+		na, errA := strconv.Atoi(pa[i])
+		nb, errB := strconv.Atoi(pb[i])
+		switch {
+		case errA == nil && errB == nil && na != nb:
+			if na < nb {
+				return -1
+			}
+			return 1
+		case (errA != nil || errB != nil) && pa[i] != pb[i]:
+			return strings.Compare(pa[i], pb[i])
+		}
+	}
+	return 0
+}
+
+// latestRelease returns the newest release on a channel.
+func latestRelease(releases []Release, channel string) (Release, bool) {
+	for _, r := range releases {
+		if r.Channel == channel {
+			return r, true
+		}
+	}
+	return Release{}, false
+}
+
+// handleReleases lists releases as JSON, newest first, optionally only
+// those on ?channel=<name>.
+func handleReleases(w http.ResponseWriter, r *http.Request) {
+	releases, err := loadReleases()
+	if err != nil {
+		log.Printf("%v", err)
+		http.Error(w, "Failed to list releases", http.StatusInternalServerError)
+		return
+	}
+	channel := r.URL.Query().Get("channel")
+	listed := []Release{}
+	for _, rel := range releases {
+		if channel == "" || rel.Channel == channel {
+			listed = append(listed, rel)
+		}
+	}
+	if channel == "" {
+		channel = defaultChannel
+	}
+	resp := struct {
+		Latest   string    `json:"latest,omitempty"` // Newest on the requested or default channel
+		Releases []Release `json:"releases"`
+	}{Releases: listed}
+	if latest, ok := latestRelease(releases, channel); ok {
+		resp.Latest = latest.Version
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleLatestRelease returns the newest release on ?channel=<name>
+// (default stable). With ?platform=<os>-<arch> only that build is listed,
+// and it is a 404 if the release has none.
+func handleLatestRelease(w http.ResponseWriter, r *http.Request) {
+	releases, err := loadReleases()
+	if err != nil {
+		log.Printf("%v", err)
+		http.Error(w, "Failed to list releases", http.StatusInternalServerError)
+		return
+	}
+	channel := r.URL.Query().Get("channel")
+	if channel == "" {
+		channel = defaultChannel
+	}
+	latest, ok := latestRelease(releases, channel)
+	if !ok {
+		http.Error(w, "No release on channel "+channel, http.StatusNotFound)
+		return
+	}
+	if platform := r.URL.Query().Get("platform"); platform != "" {
+		b, ok := latest.Build(platform)
+		if !ok {
+			http.Error(w, "No build for "+platform, http.StatusNotFound)
+			return
+		}
+		latest.Builds = []Build{b}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(latest)
+}
+
+// handleReleaseFile serves /releases/<version>/<file>. The checksum is the
+// ETag, and http.ServeContent handles Range and conditional requests.
+func handleReleaseFile(w http.ResponseWriter, r *http.Request) {
+	version, file := r.PathValue("version"), r.PathValue("file")
+	if !validName(version) || !buildName.MatchString(file) || strings.ContainsAny(file, `/\`) {
+		http.Error(w, "Invalid release file", http.StatusBadRequest)
+		return
+	}
+	path := filepath.Join(releasesDir, version, file)
+	f, err := os.Open(path)
+	if err != nil {
+		http.Error(w, "Release file not found", http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		http.Error(w, "Release file not found", http.StatusNotFound)
+		return
+	}
+	sum, err := fileSHA256(path, info)
+	if err != nil {
+		log.Printf("failed to hash %s: %v", path, err)
+		http.Error(w, "Failed to read release file", http.StatusInternalServerError)
+		return
+	}
+	// Builds can take longer than the server's write timeout to download
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("failed to clear write deadline: %v", err)
+	}
+	w.Header().Set("ETag", `"`+sum+`"`)
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file))
+	http.ServeContent(w, r, file, info.ModTime(), f)
+}
+
+// handleDownload redirects to the latest build for ?platform=<os>-<arch>,
+// or for the operating system in the User-Agent when it is not given.
+func handleDownload(w http.ResponseWriter, r *http.Request) {
+	releases, err := loadReleases()
+	if err != nil {
+		log.Printf("%v", err)
+		http.Error(w, "Failed to list releases", http.StatusInternalServerError)
+		return
+	}
+	channel := r.URL.Query().Get("channel")
+	if channel == "" {
+		channel = defaultChannel
+	}
+	latest, ok := latestRelease(releases, channel)
+	if !ok {
+		http.Error(w, "No release available", http.StatusNotFound)
+		return
+	}
+	if platform := r.URL.Query().Get("platform"); platform != "" {
+		b, ok := latest.Build(platform)
+		if !ok {
+			http.Error(w, "No build for "+platform, http.StatusNotFound)
+			return
+		}
+		http.Redirect(w, r, b.URL, http.StatusFound)
+		return
+	}
+	if goos := userAgentOS(r.UserAgent()); goos != "" {
+		for _, b := range latest.Builds {
+			if strings.HasPrefix(b.Platform, goos+"-") {
+				http.Redirect(w, r, b.URL, http.StatusFound)
+				return
+			}
+		}
+	}
+	// Let the visitor pick from the download page
+	http.Redirect(w, r, "/#downloads", http.StatusFound)
+}
+
+// userAgentOS guesses the GOOS of a browser, or "" if it cannot tell.
+func userAgentOS(ua string) string {
+	switch {
+	case strings.Contains(ua, "Windows"):
+		return "windows"
+	case strings.Contains(ua, "Mac OS X") || strings.Contains(ua, "Macintosh"):
+		return "darwin"
+	case strings.Contains(ua, "Linux") && !strings.Contains(ua, "Android"):
+		return "linux"
+	}
+	return ""
+}
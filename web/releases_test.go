@@ -0,0 +1,170 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// withReleases points releasesDir at a temporary directory holding files,
+// keyed by path relative to it.
+func withReleases(t *testing.T, files map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := releasesDir
+	releasesDir = dir
+	t.Cleanup(func() { releasesDir = old })
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// The game's updater orders versions the same way; both run these vectors.
+func TestCompareVersions(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "update", "testdata", "versions.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests []struct {
+		A    string `json:"a"`
+		B    string `json:"b"`
+		Want int    `json:"want"`
+	}
+	if err := json.Unmarshal(data, &tests); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.A, tt.B); got != tt.Want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.A, tt.B, got, tt.Want)
+		}
+		if got := compareVersions(tt.B, tt.A); got != -tt.Want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.B, tt.A, got, -tt.Want)
+		}
+	}
+}
+
+func TestLoadReleases(t *testing.T) {
+	withReleases(t, map[string]string{
+		"1.9.0/main-linux-amd64":         "linux 1.9",
+		"1.10.0/main-linux-amd64":        "linux 1.10",
+		"1.10.0/main-windows-amd64.exe":  "windows 1.10",
+		"1.10.0/CHANGELOG.md":            "\n- Faster chunks\n",
+		"1.10.0/notes.txt":               "not a build",
+		"1.10.0-beta.1/main-linux-amd64": "beta",
+		"1.10.0-beta.1/release.json":     `{"channel": "beta", "date": "2026-10-01", "version": "ignored"}`,
+		"2.0.0/main-linux-amd64":         "broken",
+		"2.0.0/release.json":             `{"channel": `,
+		"0.1.0/CHANGELOG.md":             "No builds yet",
+		".staging/main-linux-amd64":      "hidden",
+		"stray-file-linux-amd64":         "not a directory",
+	})
+	releases, err := loadReleases()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var versions []string
+	for _, r := range releases {
+		versions = append(versions, r.Version)
+	}
+	// 2.0.0 has a bad release.json and 0.1.0 has no builds
+	if want := []string{"1.10.0", "1.10.0-beta.1", "1.9.0"}; !slices.Equal(versions, want) {
+		t.Fatalf("versions %v, want %v", versions, want)
+	}
+
+	latest := releases[0]
+	if latest.Channel != defaultChannel || latest.Changelog != "- Faster chunks" {
+		t.Errorf("1.10.0 channel %q changelog %q", latest.Channel, latest.Changelog)
+	}
+	want := []Build{
+		{Platform: "linux-amd64", File: "main-linux-amd64", Size: 10, SHA256: sha256Hex("linux 1.10"), URL: "/releases/1.10.0/main-linux-amd64"},
+		{Platform: "windows-amd64", File: "main-windows-amd64.exe", Size: 12, SHA256: sha256Hex("windows 1.10"), URL: "/releases/1.10.0/main-windows-amd64.exe"},
+	}
+	if !slices.Equal(latest.Builds, want) {
+		t.Errorf("builds %+v, want %+v", latest.Builds, want)
+	}
+	if beta := releases[1]; beta.Channel != "beta" || beta.Date != "2026-10-01" {
+		t.Errorf("beta release %+v", beta)
+	}
+	if r, ok := latestRelease(releases, "beta"); !ok || r.Version != "1.10.0-beta.1" {
+		t.Errorf("latest beta %q, %v", r.Version, ok)
+	}
+
+	releasesDir = filepath.Join(releasesDir, "missing")
+	if releases, err := loadReleases(); err != nil || len(releases) != 0 {
+		t.Errorf("missing directory gave %v, %v; want no releases", releases, err)
+	}
+}
+
+func TestReleaseFile(t *testing.T) {
+	const build = "0123456789abcdef"
+	withReleases(t, map[string]string{"1.0.0/main-linux-amd64": build})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /releases/{version}/{file}", handleReleaseFile)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	etag := `"` + sha256Hex(build) + `"`
+
+	tests := []struct {
+		name     string
+		path     string
+		header   [2]string
+		status   int
+		body     string
+		wantETag bool
+	}{
+		{"whole file", "/releases/1.0.0/main-linux-amd64", [2]string{}, http.StatusOK, build, true},
+		{"matching ETag", "/releases/1.0.0/main-linux-amd64", [2]string{"If-None-Match", etag}, http.StatusNotModified, "", true},
+		{"stale ETag", "/releases/1.0.0/main-linux-amd64", [2]string{"If-None-Match", `"old"`}, http.StatusOK, build, true},
+		{"range", "/releases/1.0.0/main-linux-amd64", [2]string{"Range", "bytes=4-7"}, http.StatusPartialContent, "4567", true},
+		{"resume", "/releases/1.0.0/main-linux-amd64", [2]string{"Range", "bytes=12-"}, http.StatusPartialContent, "cdef", true},
+		{"range past the end", "/releases/1.0.0/main-linux-amd64", [2]string{"Range", "bytes=99-"}, http.StatusRequestedRangeNotSatisfiable, "", false},
+		{"missing build", "/releases/1.0.0/main-darwin-arm64", [2]string{}, http.StatusNotFound, "", false},
+		{"missing version", "/releases/9.9.9/main-linux-amd64", [2]string{}, http.StatusNotFound, "", false},
+		{"not a build name", "/releases/1.0.0/CHANGELOG.md", [2]string{}, http.StatusBadRequest, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, srv.URL+tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.header[0] != "" {
+				req.Header.Set(tt.header[0], tt.header[1])
+			}
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status %d, want %d (%s)", resp.StatusCode, tt.status, strings.TrimSpace(string(body)))
+			}
+			if tt.body != "" && string(body) != tt.body {
+				t.Errorf("body %q, want %q", body, tt.body)
+			}
+			if got := resp.Header.Get("ETag"); tt.wantETag && got != etag {
+				t.Errorf("ETag %q, want %q", got, etag)
+			}
+		})
+	}
+}
@@ -1,33 +1,44 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"html/template"
+	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
+// savesDir holds world saves written by the game.
+const savesDir = "../saves"
+
 func main() {
+	flag.Parse()
+	tileRenders.slots = make(chan struct{}, max(*mapRenders, 1))
+
 	// Serve static files from web/ (e.g., index.html)
 	http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("./"))))
 
-	// Serve game binary from parent directory
-	http.HandleFunc("/download", func(w http.ResponseWriter, r *http.Request) {
-		binaryPath := "../bin/main"
-		if _, err := os.Stat(binaryPath); os.IsNotExist(err) {
-			http.Error(w, "Game binary not found", http.StatusNotFound)
-			return
-		}
+	// Serve versioned builds from the releases directory
+	http.HandleFunc("/download", handleDownload)
+	http.HandleFunc("GET /releases/{version}/{file}", handleReleaseFile)
+	http.HandleFunc("/api/releases", handleReleases)
+	http.HandleFunc("/api/releases/latest", handleLatestRelease)
+
+	// Serve per-player stats for a world as JSON
+	http.HandleFunc("/api/stats", handleStats)
 
-		w.Header().Set("Content-Type", "application/octet-stream")
-		w.Header().Set("Content-Disposition", "attachment; filename=main")
-		http.ServeFile(w, r, binaryPath)
-	})
+	// Serve map tiles and player positions for the map viewer
+	http.HandleFunc("GET /map/{world}/{z}/{x}/{y}", handleTile)
+	http.HandleFunc("/api/players", handlePlayers)
 
-	// Serve index.html at root
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, "./index.html")
-	})
+	// Render index.html, with the download list, at root
+	http.HandleFunc("/", handleIndex)
 
 	// Start server on port 8080 with timeout settings
 	server := &http.Server{
@@ -41,3 +52,92 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// indexFuncs are the helpers available to index.html.
+var indexFuncs = template.FuncMap{
+	"size": func(n int64) string {
+		switch {
+		case n >= 1<<20:
+			return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+		case n >= 1<<10:
+			return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+		}
+		return fmt.Sprintf("%d B", n)
+	},
+}
+
+// handleIndex renders index.html with the latest stable release and the
+// older or pre-release ones. The template is parsed per request so edits
+// show up without a restart.
+func handleIndex(w http.ResponseWriter, r *http.Request) {
+	tmpl, err := template.New("index.html").Funcs(indexFuncs).ParseFiles("./index.html")
+	if err != nil {
+		log.Printf("failed to parse index.html: %v", err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+		return
+	}
+	releases, err := loadReleases()
+	if err != nil {
+		log.Printf("%v", err)
+	}
+	var data struct {
+		Latest *Release
+		Others []Release
+	}
+	if latest, ok := latestRelease(releases, defaultChannel); ok {
+		data.Latest = &latest
+	}
+	for _, rel := range releases {
+		if data.Latest == nil || rel.Version != data.Latest.Version {
+			data.Others = append(data.Others, rel)
+		}
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		log.Printf("failed to render index.html: %v", err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	buf.WriteTo(w)
+}
+
+// handleStats returns every player's stats file for ?world=<name>, keyed by
+// player profile.
+func handleStats(w http.ResponseWriter, r *http.Request) {
+	serveWorldFiles(w, r, "stats")
+}
+
+// serveWorldFiles returns the JSON files in a subdirectory of the world
+// named by ?world=<name> as one object, keyed by file name.
+func serveWorldFiles(w http.ResponseWriter, r *http.Request, subdir string) {
+	world := r.URL.Query().Get("world")
+	if world == "" {
+		world = "world"
+	}
+	if !validName(world) {
+		http.Error(w, "Invalid world name", http.StatusBadRequest)
+		return
+	}
+	paths, err := filepath.Glob(filepath.Join(savesDir, world, subdir, "*.json"))
+	if err != nil {
+		http.Error(w, "Failed to list "+subdir, http.StatusInternalServerError)
+		return
+	}
+	files := make(map[string]json.RawMessage)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil || !json.Valid(data) {
+			continue
+		}
+		files[strings.TrimSuffix(filepath.Base(path), ".json")] = data
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(files)
+}
+
+// validName reports whether a world or release name from a request is
+// safe to join to a directory.
+func validName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
@@ -0,0 +1,42 @@
+package world
+
+import "something/block"
+
+// Biome names returned by World.Biome.
+const (
+	BiomePlains = "plains"
+	BiomeMeadow = "meadow" // Wet lowland
+	BiomeHills  = "hills"  // High terrain
+)
+
+const (
+	hillsHeight     = 12   // Surface height at which terrain counts as hills
+	meadowMoisture  = 0.15 // Moisture noise above which lowland is meadow
+	moistureScale   = 80.0 // Blocks per moisture noise unit
+	moistureSeedOff = 1    // Moisture noise uses seed+1 so it is independent of height
+)
+
+// MaxLight is the light level of a block open to the sky.
+const MaxLight = 15
+
+// BiomeAt returns the biome of a column from the seed alone, so it does not
+// depend on which chunks are loaded or edited.
+func BiomeAt(seed int64, x, z int) string {
+	return NewGenerator(seed).Biome(x, z)
+}
+
+// Biome returns the biome of the column at world block coordinates.
+func (w *World) Biome(x, z int) string {
+	return w.generator().Biome(x, z)
+}
+
+// Light returns the sky light at a block: MaxLight when no solid block is
+// above it, 0 otherwise. There are no block light sources yet.
+func (w *World) Light(x, y, z int) int {
+	for yy := max(y+1, 0); yy < ChunkSize; yy++ {
+		if block.Blocks[w.GetBlock(x, yy, z)].IsSolid() {
+			return 0
+		}
+	}
+	return MaxLight
+}
@@ -1,7 +1,10 @@
 package world
 
 import (
+	"time"
+
 	"something/block"
+	"something/profile"
 
 	"github.com/aquilax/go-perlin"
 	"github.com/go-gl/gl/v4.6-core/gl"
@@ -12,21 +15,16 @@ type Chunk struct {
 	VAO         uint32
 	VBO         uint32
 	VertexCount int32
+	MeshedAt    time.Time // When the mesh was last uploaded, for the mesh-age view
 }
 
-func NewChunk(x, z int32) *Chunk {
+func NewChunk(x, z int32, seed int64) *Chunk {
+	defer profile.Begin("chunk.generate")()
 	var c Chunk
-	p := perlin.NewPerlin(2, 2, 3, 42)
+	p := perlin.NewPerlin(2, 2, 3, seed)
 	for i := 0; i < ChunkSize; i++ {
 		for k := 0; k < ChunkSize; k++ {
-			worldX := float64(x*ChunkSize+int32(i)) / 50.0
-			worldZ := float64(z*ChunkSize+int32(k)) / 50.0
-			height := int(p.Noise2D(worldX, worldZ)*10 + 8)
-			if height < 0 {
-				height = 0
-			} else if height > ChunkSize-1 {
-				height = ChunkSize - 1
-			}
+			height := terrainHeight(p, int(x)*ChunkSize+i, int(z)*ChunkSize+k)
 			for j := 0; j < ChunkSize; j++ {
 				if j < height-2 {
 					c.Blocks[i][j][k] = block.BlockStone
@@ -43,6 +41,12 @@ func NewChunk(x, z int32) *Chunk {
 	return &c
 }
 
+// terrainHeight is the generated surface block height of a column.
+func terrainHeight(p *perlin.Perlin, x, z int) int {
+	height := int(p.Noise2D(float64(x)/50.0, float64(z)/50.0)*10 + 8)
+	return max(0, min(ChunkSize-1, height))
+}
+
 func (c *Chunk) GenerateMesh() []float32 {
 	var mesh []float32
 	for x := 0; x < ChunkSize; x++ {
@@ -72,9 +76,14 @@ func (c *Chunk) GenerateMesh() []float32 {
 }
 
 func (c *Chunk) UploadMesh() {
+	end := profile.Begin("chunk.mesh")
 	mesh := c.GenerateMesh()
+	end()
+	end = profile.Begin("chunk.upload")
 	c.VAO, c.VBO = uploadMesh(mesh)
+	end()
 	c.VertexCount = int32(len(mesh) / 8)
+	c.MeshedAt = time.Now()
 }
 
 func (c *Chunk) Cleanup() {
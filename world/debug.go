@@ -0,0 +1,44 @@
+package world
+
+import (
+	"math"
+	"time"
+
+	"something/lines"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// meshAgeSpan is the mesh age at which the mesh-age tint stops changing.
+const meshAgeSpan = 20 * time.Second
+
+var (
+	borderColor     = mgl32.Vec4{0.3, 0.6, 1, 0.8}
+	borderHighlight = mgl32.Vec4{1, 0.9, 0.2, 1}
+	freshMeshColor  = mgl32.Vec4{1, 0.2, 0.1, 0.5}
+	oldMeshColor    = mgl32.Vec4{0.1, 0.3, 1, 0.5}
+)
+
+// DrawChunkBorders submits a post at each corner of every loaded chunk and
+// outlines the chunk containing pos.
+func (w *World) DrawChunkBorders(pos mgl32.Vec3) {
+	current, _, _ := chunkCoords(int(math.Floor(float64(pos.X()))), int(math.Floor(float64(pos.Z()))))
+	for key := range w.Chunks {
+		x, z := float32(key[0]*ChunkSize), float32(key[1]*ChunkSize)
+		if key == current {
+			lines.Box(mgl32.Vec3{x, 0, z}, mgl32.Vec3{x + ChunkSize, ChunkSize, z + ChunkSize}, borderHighlight)
+			continue
+		}
+		for _, corner := range [][2]float32{{0, 0}, {ChunkSize, 0}, {0, ChunkSize}, {ChunkSize, ChunkSize}} {
+			base := mgl32.Vec3{x + corner[0], 0, z + corner[1]}
+			lines.Line(base, base.Add(mgl32.Vec3{0, ChunkSize, 0}), borderColor)
+		}
+	}
+}
+
+// meshAgeColor fades from red for a fresh mesh to blue for one older than
+// meshAgeSpan.
+func meshAgeColor(age time.Duration) mgl32.Vec4 {
+	t := float32(min(age.Seconds()/meshAgeSpan.Seconds(), 1))
+	return freshMeshColor.Mul(1 - t).Add(oldMeshColor.Mul(t))
+}
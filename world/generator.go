@@ -0,0 +1,43 @@
+package world
+
+import "github.com/aquilax/go-perlin"
+
+// Generator answers questions about a seed's generated terrain one column
+// at a time, without generating chunks. Results ignore edits.
+type Generator struct {
+	Seed     int64
+	height   *perlin.Perlin
+	moisture *perlin.Perlin
+}
+
+func NewGenerator(seed int64) *Generator {
+	return &Generator{
+		Seed:     seed,
+		height:   perlin.NewPerlin(2, 2, 3, seed),
+		moisture: perlin.NewPerlin(2, 2, 3, seed+moistureSeedOff),
+	}
+}
+
+// Height returns the y of the column's surface block, which is grass.
+func (g *Generator) Height(x, z int) int {
+	return terrainHeight(g.height, x, z)
+}
+
+// Biome returns the biome of a column.
+func (g *Generator) Biome(x, z int) string {
+	if g.Height(x, z) >= hillsHeight {
+		return BiomeHills
+	}
+	if g.moisture.Noise2D(float64(x)/moistureScale, float64(z)/moistureScale) > meadowMoisture {
+		return BiomeMeadow
+	}
+	return BiomePlains
+}
+
+// generator returns the world's cached Generator, rebuilt if Seed changed.
+func (w *World) generator() *Generator {
+	if w.gen == nil || w.gen.Seed != w.Seed {
+		w.gen = NewGenerator(w.Seed)
+	}
+	return w.gen
+}
@@ -0,0 +1,45 @@
+package world
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// InfoFile is the name of a world's info file inside its save directory.
+const InfoFile = "world.json"
+
+// Info is what other tools need to know about a saved world, such as the
+// seed for rendering its map.
+type Info struct {
+	Seed int64 `json:"seed"`
+}
+
+// LoadInfo reads a world's info file into info. A missing file is not an
+// error and leaves info unchanged, so callers can fill in defaults first.
+func LoadInfo(dir string, info *Info) error {
+	data, err := os.ReadFile(filepath.Join(dir, InfoFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read world info: %w", err)
+	}
+	if err := json.Unmarshal(data, info); err != nil {
+		return fmt.Errorf("failed to parse world info: %w", err)
+	}
+	return nil
+}
+
+// SaveInfo writes a world's info file.
+func SaveInfo(dir string, info Info) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create world directory: %w", err)
+	}
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode world info: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, InfoFile), data, 0o644)
+}
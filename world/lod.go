@@ -0,0 +1,220 @@
+package world
+
+import (
+	"math"
+
+	"something/block"
+	"something/metrics"
+	"something/profile"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	lodMaxLevel = 4 // Coarsest tiles are 2^4 chunks wide
+	lodFactor   = 2 // A tile splits while it is closer than lodFactor times its width
+	lodCells    = ChunkSize
+)
+
+// lodKey identifies a tile: its level and position in chunks. A level L
+// tile is 2^L chunks wide and samples the terrain every 2^L blocks.
+type lodKey struct {
+	level int
+	x, z  int
+}
+
+// lodTile is the uploaded heightmap mesh of one tile.
+type lodTile struct {
+	vao, vbo    uint32
+	vertexCount int32
+}
+
+// UpdateLOD picks the heightmap tiles that fill the view between the
+// loaded chunks and LODDistance, uploading at most LODTilesPerFrame new
+// ones, and frees tiles no longer needed.
+func (w *World) UpdateLOD(pos mgl32.Vec3) {
+	if w.Headless || w.LODDistance <= 0 {
+		return
+	}
+	defer profile.Begin("world.lod")()
+	if w.lodTiles == nil {
+		w.lodTiles = make(map[lodKey]*lodTile)
+	}
+	current, _, _ := chunkCoords(int(math.Floor(float64(pos.X()))), int(math.Floor(float64(pos.Z()))))
+	w.lodVisible = w.lodVisible[:0]
+	w.selectLOD(current, func(k lodKey) {
+		w.lodVisible = append(w.lodVisible, k)
+	})
+
+	wanted := make(map[lodKey]bool, len(w.lodVisible))
+	built := 0
+	for _, k := range w.lodVisible {
+		wanted[k] = true
+		if _, ok := w.lodTiles[k]; ok || (w.LODTilesPerFrame > 0 && built >= w.LODTilesPerFrame) {
+			continue
+		}
+		mesh := lodMesh(w.heightAt, k)
+		t := &lodTile{vertexCount: int32(len(mesh) / 8)}
+		t.vao, t.vbo = uploadMesh(mesh)
+		w.lodTiles[k] = t
+		built++
+	}
+	for k, t := range w.lodTiles {
+		if !wanted[k] {
+			gl.DeleteVertexArrays(1, &t.vao)
+			gl.DeleteBuffers(1, &t.vbo)
+			delete(w.lodTiles, k)
+		}
+	}
+	metrics.Set(metrics.LODTiles, float64(len(w.lodTiles)))
+}
+
+// selectLOD walks a quadtree of tiles around the player's chunk and calls
+// emit for each leaf to draw. Tiles split near the player, so neighbouring
+// leaves differ by at most one level. Level 0 leaves inside the chunk radius
+// are skipped once their chunk is loaded.
+func (w *World) selectLOD(center [2]int, emit func(lodKey)) {
+	radius := (w.LODDistance + ChunkSize - 1) / ChunkSize
+	rootSize := 1 << lodMaxLevel
+	for x := floorDiv(center[0]-radius, rootSize); x <= floorDiv(center[0]+radius, rootSize); x++ {
+		for z := floorDiv(center[1]-radius, rootSize); z <= floorDiv(center[1]+radius, rootSize); z++ {
+			w.selectTile(center, radius, lodKey{level: lodMaxLevel, x: x * rootSize, z: z * rootSize}, emit)
+		}
+	}
+}
+
+func (w *World) selectTile(center [2]int, radius int, k lodKey, emit func(lodKey)) {
+	size := 1 << k.level
+	dx := max(k.x-center[0], 0, center[0]-(k.x+size-1))
+	dz := max(k.z-center[1], 0, center[1]-(k.z+size-1))
+	nearest := max(dx, dz)
+	if nearest > radius {
+		return
+	}
+	if k.level > 0 && (nearest <= w.ChunkRadius || nearest < lodFactor*size) {
+		half := size / 2
+		for _, off := range [][2]int{{0, 0}, {half, 0}, {0, half}, {half, half}} {
+			w.selectTile(center, radius, lodKey{level: k.level - 1, x: k.x + off[0], z: k.z + off[1]}, emit)
+		}
+		return
+	}
+	if k.level == 0 {
+		if _, loaded := w.Chunks[[2]int{k.x, k.z}]; loaded {
+			return
+		}
+	}
+	emit(k)
+}
+
+// heightAt is the generated surface height of a column, ignoring edits.
+func (w *World) heightAt(x, z int) int {
+	return w.generator().Height(x, z)
+}
+
+// renderLOD draws the visible tiles with the chunk program already bound.
+func (w *World) renderLOD() int {
+	vertices := 0
+	w.Program.SetVec4("tint", mgl32.Vec4{})
+	for _, k := range w.lodVisible {
+		t, ok := w.lodTiles[k]
+		if !ok {
+			continue
+		}
+		model := mgl32.Translate3D(float32(k.x*ChunkSize), 0, float32(k.z*ChunkSize))
+		w.Program.SetMat4("model", model)
+		gl.BindVertexArray(t.vao)
+		gl.DrawArrays(gl.TRIANGLES, 0, t.vertexCount)
+		metrics.Add(metrics.DrawCalls, 1)
+		vertices += int(t.vertexCount)
+	}
+	return vertices
+}
+
+func (w *World) cleanupLOD() {
+	for k, t := range w.lodTiles {
+		gl.DeleteVertexArrays(1, &t.vao)
+		gl.DeleteBuffers(1, &t.vbo)
+		delete(w.lodTiles, k)
+	}
+}
+
+// lodMesh builds a tile as blocky columns, one per cell of step blocks,
+// in the chunk vertex format relative to the tile's corner. Walls drop to
+// lower neighbours; on the tile border they drop to the ground as skirts
+// so coarser neighbours never show a gap.
+func lodMesh(height func(x, z int) int, k lodKey) []float32 {
+	step := 1 << k.level
+	x0, z0 := k.x*ChunkSize, k.z*ChunkSize
+	// Heights of the cells plus a one-cell border, sampled at cell centres
+	var h [lodCells + 2][lodCells + 2]float32
+	for i := range h {
+		for j := range h[i] {
+			h[i][j] = float32(height(x0+(i-1)*step+step/2, z0+(j-1)*step+step/2) + 1)
+		}
+	}
+	grass := block.Blocks[block.BlockGrass]
+	dirt := block.Blocks[block.BlockDirt]
+	tu0, tv0, tu1, tv1 := grass.GetUVs("top")
+	su0, sv0, su1, sv1 := dirt.GetUVs("front")
+	s := float32(step)
+	var mesh []float32
+	for i := 1; i <= lodCells; i++ {
+		for j := 1; j <= lodCells; j++ {
+			top := h[i][j]
+			x, z := float32(i-1)*s, float32(j-1)*s
+			mesh = appendQuad(mesh,
+				mgl32.Vec3{x, top, z}, mgl32.Vec3{x, top, z + s}, mgl32.Vec3{x + s, top, z + s}, mgl32.Vec3{x + s, top, z},
+				mgl32.Vec3{0, 1, 0}, tu0, tv0, tu1, tv1)
+			// Neighbour height per side; border sides drop to the ground
+			sides := []struct {
+				neighbour float32
+				border    bool
+				a, b      mgl32.Vec3 // Ends of the wall's bottom edge
+				normal    mgl32.Vec3
+			}{
+				{h[i+1][j], i == lodCells, mgl32.Vec3{x + s, 0, z + s}, mgl32.Vec3{x + s, 0, z}, mgl32.Vec3{1, 0, 0}},
+				{h[i-1][j], i == 1, mgl32.Vec3{x, 0, z}, mgl32.Vec3{x, 0, z + s}, mgl32.Vec3{-1, 0, 0}},
+				{h[i][j+1], j == lodCells, mgl32.Vec3{x, 0, z + s}, mgl32.Vec3{x + s, 0, z + s}, mgl32.Vec3{0, 0, 1}},
+				{h[i][j-1], j == 1, mgl32.Vec3{x + s, 0, z}, mgl32.Vec3{x, 0, z}, mgl32.Vec3{0, 0, -1}},
+			}
+			for _, side := range sides {
+				bottom := side.neighbour
+				if side.border {
+					bottom = 0
+				}
+				if bottom >= top {
+					continue
+				}
+				a, b := side.a, side.b
+				mesh = appendQuad(mesh,
+					mgl32.Vec3{a[0], bottom, a[2]}, mgl32.Vec3{a[0], top, a[2]}, mgl32.Vec3{b[0], top, b[2]}, mgl32.Vec3{b[0], bottom, b[2]},
+					side.normal, su0, sv0, su1, sv1)
+			}
+		}
+	}
+	return mesh
+}
+
+// appendQuad adds two triangles for the corners a, b, c, d, given in order
+// around the quad, in the chunk vertex format.
+func appendQuad(mesh []float32, a, b, c, d, n mgl32.Vec3, u0, v0, u1, v1 float32) []float32 {
+	vertex := func(p mgl32.Vec3, u, v float32) {
+		mesh = append(mesh, p[0], p[1], p[2], n[0], n[1], n[2], u, v)
+	}
+	vertex(a, u0, v0)
+	vertex(b, u0, v1)
+	vertex(c, u1, v1)
+	vertex(a, u0, v0)
+	vertex(c, u1, v1)
+	vertex(d, u1, v0)
+	return mesh
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
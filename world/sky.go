@@ -0,0 +1,134 @@
+package world
+
+import (
+	"fmt"
+	"math"
+
+	"something/metrics"
+	"something/shader"
+
+	"github.com/go-gl/gl/v4.6-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// sunTilt leans the sun's path toward the south so it is never exactly
+// overhead, which keeps noon shading from being flat.
+const sunTilt = 0.35
+
+// fogStartFraction is where fog begins as a fraction of the view distance.
+const fogStartFraction = 0.6
+
+// Sky is the lighting and colours for one moment of the day.
+type Sky struct {
+	SunDir   mgl32.Vec3 // Unit vector toward the sun
+	MoonDir  mgl32.Vec3 // Unit vector toward the moon
+	Zenith   mgl32.Vec3 // Colour straight up
+	Horizon  mgl32.Vec3 // Colour at the horizon; also the fog colour
+	LightDir mgl32.Vec3 // Direction light travels from the brighter of sun and moon
+	Light    mgl32.Vec3 // Diffuse light colour and intensity
+	Ambient  float32
+}
+
+var (
+	dayZenith     = mgl32.Vec3{0.25, 0.5, 0.9}
+	dayHorizon    = mgl32.Vec3{0.65, 0.8, 0.95}
+	sunsetZenith  = mgl32.Vec3{0.3, 0.3, 0.55}
+	sunsetHorizon = mgl32.Vec3{0.95, 0.55, 0.3}
+	nightZenith   = mgl32.Vec3{0.01, 0.01, 0.04}
+	nightHorizon  = mgl32.Vec3{0.05, 0.06, 0.12}
+	sunLight      = mgl32.Vec3{1, 0.97, 0.9}
+	sunsetLight   = mgl32.Vec3{1, 0.6, 0.35}
+	moonLight     = mgl32.Vec3{0.25, 0.3, 0.45}
+)
+
+// Tick advances world time by dt seconds.
+func (w *World) Tick(dt float32) {
+	w.Time += float64(dt)
+	hour := w.TimeOfDay()
+	metrics.SetText(metrics.WorldTime, fmt.Sprintf("Time: day %d, %02d:%02d", w.Day(), int(hour), int(math.Mod(hour, 1)*60)))
+}
+
+// Sky returns the sky at the current time of day.
+func (w *World) Sky() Sky {
+	return SkyAt(w.TimeOfDay())
+}
+
+// SkyAt returns the sky at an hour of the day. The sun rises in the east
+// (+X) at 06:00 and sets in the west at 18:00; the moon is opposite.
+func SkyAt(hour float64) Sky {
+	angle := (hour - 6) / 24 * 2 * math.Pi
+	sun := mgl32.Vec3{float32(math.Cos(angle)), float32(math.Sin(angle)), sunTilt}.Normalize()
+	s := Sky{SunDir: sun, MoonDir: sun.Mul(-1)}
+
+	elevation := sun.Y()
+	day := smoothstep(-0.15, 0.25, elevation)
+	sunset := max(0, 1-abs32(elevation)/0.3) // Peaks with the sun on the horizon
+	s.Zenith = mix(nightZenith, dayZenith, day)
+	s.Horizon = mix(nightHorizon, dayHorizon, day)
+	s.Zenith = mix(s.Zenith, sunsetZenith, sunset*0.6)
+	s.Horizon = mix(s.Horizon, sunsetHorizon, sunset*0.8)
+
+	s.Ambient = 0.05 + 0.25*day
+	if elevation > 0 {
+		s.LightDir = sun.Mul(-1)
+		s.Light = mix(sunsetLight, sunLight, smoothstep(0, 0.35, elevation)).Mul(smoothstep(0, 0.1, elevation))
+	} else {
+		s.LightDir = sun
+		s.Light = moonLight.Mul(smoothstep(0, 0.1, -elevation))
+	}
+	return s
+}
+
+// RenderSky draws the sky gradient, sun and moon behind everything. Call it
+// after clearing and before any other geometry.
+func (w *World) RenderSky(view, projection mgl32.Mat4) {
+	sky := w.Sky()
+	rotation := view
+	rotation.SetCol(3, mgl32.Vec4{0, 0, 0, 1}) // The sky is infinitely far away
+	inverse := projection.Mul4(rotation).Inv()
+	w.skyProgram.Use()
+	w.skyProgram.SetMat4("inverseViewProjection", inverse)
+	w.skyProgram.SetVec3("zenith", sky.Zenith)
+	w.skyProgram.SetVec3("horizon", sky.Horizon)
+	w.skyProgram.SetVec3("sunDir", sky.SunDir)
+	w.skyProgram.SetVec3("moonDir", sky.MoonDir)
+	depthTest := gl.IsEnabled(gl.DEPTH_TEST)
+	gl.Disable(gl.DEPTH_TEST)
+	gl.BindVertexArray(w.skyVAO)
+	gl.DrawArrays(gl.TRIANGLES, 0, 3)
+	metrics.Add(metrics.DrawCalls, 1)
+	gl.BindVertexArray(0)
+	if depthTest {
+		gl.Enable(gl.DEPTH_TEST)
+	}
+}
+
+// initSky creates the sky shader and the empty VAO its full-screen
+// triangle is drawn with.
+func (w *World) initSky() error {
+	program, err := shader.Load("sky")
+	if err != nil {
+		return err
+	}
+	w.skyProgram = program
+	gl.GenVertexArrays(1, &w.skyVAO)
+	return nil
+}
+
+func (w *World) cleanupSky() {
+	w.skyProgram.Delete()
+	gl.DeleteVertexArrays(1, &w.skyVAO)
+}
+
+func smoothstep(edge0, edge1, x float32) float32 {
+	t := min(max((x-edge0)/(edge1-edge0), 0), 1)
+	return t * t * (3 - 2*t)
+}
+
+func mix(a, b mgl32.Vec3, t float32) mgl32.Vec3 {
+	return a.Mul(1 - t).Add(b.Mul(t))
+}
+
+func abs32(v float32) float32 {
+	return float32(math.Abs(float64(v)))
+}
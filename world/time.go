@@ -0,0 +1,40 @@
+package world
+
+import "math"
+
+// DayLength is the real-time length of a full day in seconds.
+const DayLength = 1200.0
+
+// Named times of day in hours.
+var TimesOfDay = map[string]float64{
+	"sunrise":  6,
+	"day":      8,
+	"noon":     12,
+	"sunset":   18,
+	"night":    20,
+	"midnight": 0,
+}
+
+// TimeOfDay returns the current hour of the day, from 0 up to 24.
+func (w *World) TimeOfDay() float64 {
+	return math.Mod(w.Time, DayLength) / DayLength * 24
+}
+
+// Day returns how many full days have passed.
+func (w *World) Day() int {
+	return int(w.Time / DayLength)
+}
+
+// SetTimeOfDay moves the clock forward to the next occurrence of an hour,
+// so the day counter never runs backwards.
+func (w *World) SetTimeOfDay(hour float64) {
+	hour = math.Mod(hour, 24)
+	if hour < 0 {
+		hour += 24
+	}
+	target := float64(w.Day())*DayLength + hour/24*DayLength
+	if target < w.Time {
+		target += DayLength
+	}
+	w.Time = target
+}
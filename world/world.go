@@ -6,8 +6,14 @@ import (
 	"image/png"
 	"math"
 	"os"
+	"sort"
+	"time"
 
 	"something/block"
+	"something/metrics"
+	"something/profile"
+	"something/shader"
+	"something/shadow"
 
 	"github.com/go-gl/gl/v4.6-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
@@ -16,18 +22,45 @@ import (
 // ChunkSize defines the dimensions of a chunk (16x16x16).
 const ChunkSize = 16
 
+// DefaultSeed is the terrain seed used when a world does not set one.
+const DefaultSeed = 42
+
 // World manages chunks and rendering resources.
 type World struct {
 	Chunks      map[[2]int]*Chunk
 	ChunkRadius int
-	Program     uint32 // Chunk shader
-	Texture     uint32 // Grass texture
+	Seed        int64           // Terrain seed; 0 uses DefaultSeed
+	Program     *shader.Program // Chunk shader
+	Texture     uint32          // Grass texture
+
+	ChunksPerFrame int         // Chunks generated per UpdateChunks call; 0 generates all at once
+	Headless       bool        // Skips GL work so the world can run without a window
+	Wireframe      bool        // Draws chunk meshes as lines
+	ShowNormals    bool        // Colours terrain by surface normal
+	MeshAgeTint    bool        // Tints each chunk by how recently it was meshed
+	Shadows        *shadow.Map // Sun shadows received by chunks; nil disables them
+
+	LODDistance      int     // Blocks to which heightmap tiles extend past the chunks; 0 disables them
+	LODTilesPerFrame int     // LOD tiles built per UpdateChunks call; 0 builds all at once
+	Time             float64 // Seconds since the start of day 0; see TimeOfDay
+
+	OnChunkGenerated func(x, z int, c *Chunk) // Called after a new chunk is generated
+	OnChunkUnloaded  func(x, z int, c *Chunk) // Called before a chunk is removed
+
+	skyProgram *shader.Program
+	skyVAO     uint32
+	gen        *Generator // Terrain noise for LOD heights and biomes
+	lodTiles   map[lodKey]*lodTile
+	lodVisible []lodKey // Tiles chosen by the last UpdateLOD
 }
 
 // Init initializes the world's shader and texture.
 func (w *World) Init() error {
+	if w.Seed == 0 {
+		w.Seed = DefaultSeed
+	}
 	var err error
-	w.Program, err = createShaderProgram(vertexShaderSource, fragmentShaderSource)
+	w.Program, err = shader.Load("chunk")
 	if err != nil {
 		return err
 	}
@@ -35,50 +68,110 @@ func (w *World) Init() error {
 	if err != nil {
 		return err
 	}
-	return nil
+	return w.initSky()
 }
 
-// UpdateChunks loads/unloads chunks based on player position.
+// UpdateChunks loads/unloads chunks based on player position. At most
+// ChunksPerFrame missing chunks are generated per call, nearest first; the
+// rest stay queued for later calls.
 func (w *World) UpdateChunks(playerPos mgl32.Vec3) {
+	defer profile.Begin("world.update_chunks")()
 	playerChunkX := int(math.Floor(float64(playerPos.X() / float32(ChunkSize))))
 	playerChunkZ := int(math.Floor(float64(playerPos.Z() / float32(ChunkSize))))
+	var missing [][2]int
 	for x := playerChunkX - w.ChunkRadius; x <= playerChunkX+w.ChunkRadius; x++ {
 		for z := playerChunkZ - w.ChunkRadius; z <= playerChunkZ+w.ChunkRadius; z++ {
-			key := [2]int{x, z}
-			if _, exists := w.Chunks[key]; !exists {
-				chunk := NewChunk(int32(x), int32(z))
-				chunk.UploadMesh()
-				w.Chunks[key] = chunk
+			if _, exists := w.Chunks[[2]int{x, z}]; !exists {
+				missing = append(missing, [2]int{x, z})
 			}
 		}
 	}
+	distance := func(key [2]int) int {
+		dx, dz := key[0]-playerChunkX, key[1]-playerChunkZ
+		return dx*dx + dz*dz
+	}
+	sort.SliceStable(missing, func(i, j int) bool { return distance(missing[i]) < distance(missing[j]) })
+	generate := len(missing)
+	if w.ChunksPerFrame > 0 {
+		generate = min(generate, w.ChunksPerFrame)
+	}
+	for _, key := range missing[:generate] {
+		chunk := NewChunk(int32(key[0]), int32(key[1]), w.Seed)
+		if !w.Headless {
+			chunk.UploadMesh()
+		}
+		w.Chunks[key] = chunk
+		if w.OnChunkGenerated != nil {
+			w.OnChunkGenerated(key[0], key[1], chunk)
+		}
+	}
+	metrics.Set(metrics.ChunkQueue, float64(len(missing)-generate))
 	for key := range w.Chunks {
 		x, z := key[0], key[1]
 		if x < playerChunkX-w.ChunkRadius || x > playerChunkX+w.ChunkRadius ||
 			z < playerChunkZ-w.ChunkRadius || z > playerChunkZ+w.ChunkRadius {
-			w.Chunks[key].Cleanup()
+			if w.OnChunkUnloaded != nil {
+				w.OnChunkUnloaded(x, z, w.Chunks[key])
+			}
+			if !w.Headless {
+				w.Chunks[key].Cleanup()
+			}
 			delete(w.Chunks, key)
 		}
 	}
+	w.UpdateLOD(playerPos)
 }
 
 // Render draws all chunks using the world's shader and texture.
 func (w *World) Render(view, projection mgl32.Mat4, viewPos mgl32.Vec3) {
-	gl.UseProgram(w.Program)
+	defer profile.Begin("world.render")()
+	w.Program.Use()
 	gl.ActiveTexture(gl.TEXTURE0)
 	gl.BindTexture(gl.TEXTURE_2D, w.Texture)
-	gl.UniformMatrix4fv(gl.GetUniformLocation(w.Program, gl.Str("view\x00")), 1, false, &view[0])
-	gl.UniformMatrix4fv(gl.GetUniformLocation(w.Program, gl.Str("projection\x00")), 1, false, &projection[0])
-	gl.Uniform3f(gl.GetUniformLocation(w.Program, gl.Str("lightDir\x00")), 0.5, -1.0, 0.3)
-	gl.Uniform3f(gl.GetUniformLocation(w.Program, gl.Str("viewPos\x00")), viewPos.X(), viewPos.Y(), viewPos.Z())
+	w.Program.SetMat4("view", view)
+	w.Program.SetMat4("projection", projection)
+	sky := w.Sky()
+	w.Program.SetVec3("lightDir", sky.LightDir)
+	w.Program.SetVec3("lightColor", sky.Light)
+	w.Program.SetFloat("ambient", sky.Ambient)
+	w.Program.SetVec3("fogColor", sky.Horizon)
+	fogEnd := float32(max(w.ChunkRadius*ChunkSize, w.LODDistance))
+	w.Program.SetVec2("fogRange", mgl32.Vec2{fogEnd * fogStartFraction, fogEnd})
+	w.Program.SetVec3("viewPos", viewPos)
+	w.Shadows.Bind(w.Program, 1)
+	if w.Wireframe {
+		gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
+		defer gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+	}
+	w.Program.SetBool("showNormals", w.ShowNormals)
+	vertices := w.renderLOD()
 	for pos, chunk := range w.Chunks {
+		vertices += int(chunk.VertexCount)
+		metrics.Add(metrics.DrawCalls, 1)
 		model := mgl32.Translate3D(float32(pos[0]*ChunkSize), 0, float32(pos[1]*ChunkSize))
-		gl.UniformMatrix4fv(gl.GetUniformLocation(w.Program, gl.Str("model\x00")), 1, false, &model[0])
+		w.Program.SetMat4("model", model)
+		var tint mgl32.Vec4
+		if w.MeshAgeTint {
+			tint = meshAgeColor(time.Since(chunk.MeshedAt))
+		}
+		w.Program.SetVec4("tint", tint)
 		gl.BindVertexArray(chunk.VAO)
 		gl.DrawArrays(gl.TRIANGLES, 0, chunk.VertexCount)
 	}
 	gl.BindVertexArray(0)
 	gl.BindTexture(gl.TEXTURE_2D, 0)
+	metrics.Set(metrics.Chunks, float64(len(w.Chunks)))
+	metrics.Set(metrics.Vertices, float64(vertices))
+}
+
+// RenderDepth draws every chunk mesh for a depth-only pass such as shadows.
+func (w *World) RenderDepth(setModel func(model mgl32.Mat4)) {
+	for pos, chunk := range w.Chunks {
+		setModel(mgl32.Translate3D(float32(pos[0]*ChunkSize), 0, float32(pos[1]*ChunkSize)))
+		gl.BindVertexArray(chunk.VAO)
+		gl.DrawArrays(gl.TRIANGLES, 0, chunk.VertexCount)
+		metrics.Add(metrics.DrawCalls, 1)
+	}
 }
 
 // GetSurfaceHeight returns the y-coordinate of the topmost solid block at (x, z).
@@ -107,55 +200,151 @@ func (w *World) GetSurfaceHeight(x, z float32) float32 {
 	return 0 // No solid block found
 }
 
+// chunkCoords splits world block coordinates into a chunk key and local x/z.
+func chunkCoords(x, z int) (key [2]int, localX, localZ int) {
+	chunkX := int(math.Floor(float64(x) / ChunkSize))
+	chunkZ := int(math.Floor(float64(z) / ChunkSize))
+	return [2]int{chunkX, chunkZ}, x - chunkX*ChunkSize, z - chunkZ*ChunkSize
+}
+
+// Loaded reports whether the chunk holding a block column is loaded.
+func (w *World) Loaded(x, z int) bool {
+	key, _, _ := chunkCoords(x, z)
+	_, exists := w.Chunks[key]
+	return exists
+}
+
+// GetBlock returns the block at world block coordinates (air if not loaded).
+func (w *World) GetBlock(x, y, z int) block.BlockID {
+	if y < 0 || y >= ChunkSize {
+		return block.BlockAir
+	}
+	key, localX, localZ := chunkCoords(x, z)
+	chunk, exists := w.Chunks[key]
+	if !exists {
+		return block.BlockAir
+	}
+	return chunk.Blocks[localX][y][localZ]
+}
+
+// IsSolid reports whether the block at world block coordinates is solid.
+func (w *World) IsSolid(x, y, z int) bool {
+	return block.Blocks[w.GetBlock(x, y, z)].IsSolid()
+}
+
+// SetBlock replaces the block at world block coordinates and rebuilds the
+// chunk mesh. It returns false if the position is outside loaded chunks.
+func (w *World) SetBlock(x, y, z int, id block.BlockID) bool {
+	if y < 0 || y >= ChunkSize {
+		return false
+	}
+	key, localX, localZ := chunkCoords(x, z)
+	chunk, exists := w.Chunks[key]
+	if !exists {
+		return false
+	}
+	chunk.Blocks[localX][y][localZ] = id
+	w.remesh(chunk)
+	return true
+}
+
+// Fill sets every loaded block in the box between two corners (inclusive)
+// and remeshes each affected chunk once. It returns how many blocks changed.
+func (w *World) Fill(from, to [3]int, id block.BlockID) int {
+	lo := [3]int{min(from[0], to[0]), max(min(from[1], to[1]), 0), min(from[2], to[2])}
+	hi := [3]int{max(from[0], to[0]), min(max(from[1], to[1]), ChunkSize-1), max(from[2], to[2])}
+	changed := 0
+	dirty := make(map[*Chunk]bool)
+	for x := lo[0]; x <= hi[0]; x++ {
+		for z := lo[2]; z <= hi[2]; z++ {
+			key, localX, localZ := chunkCoords(x, z)
+			chunk, exists := w.Chunks[key]
+			if !exists {
+				continue
+			}
+			for y := lo[1]; y <= hi[1]; y++ {
+				if chunk.Blocks[localX][y][localZ] != id {
+					chunk.Blocks[localX][y][localZ] = id
+					dirty[chunk] = true
+					changed++
+				}
+			}
+		}
+	}
+	for chunk := range dirty {
+		w.remesh(chunk)
+	}
+	return changed
+}
+
+// remesh rebuilds a chunk's GL mesh after its blocks change.
+func (w *World) remesh(c *Chunk) {
+	if w.Headless {
+		return
+	}
+	c.Cleanup()
+	c.UploadMesh()
+}
+
+// Raycast steps through the voxel grid from origin along dir and returns the
+// first solid block hit and the empty block in front of it.
+func (w *World) Raycast(origin, dir mgl32.Vec3, maxDist float32) (hit, prev [3]int, ok bool) {
+	if dir.Len() == 0 {
+		return hit, prev, false
+	}
+	dir = dir.Normalize()
+	var pos, step [3]int
+	var tMax, tDelta [3]float32
+	for i := 0; i < 3; i++ {
+		pos[i] = int(math.Floor(float64(origin[i])))
+		switch {
+		case dir[i] > 0:
+			step[i] = 1
+			tMax[i] = (float32(pos[i]+1) - origin[i]) / dir[i]
+			tDelta[i] = 1 / dir[i]
+		case dir[i] < 0:
+			step[i] = -1
+			tMax[i] = (origin[i] - float32(pos[i])) / -dir[i]
+			tDelta[i] = 1 / -dir[i]
+		default:
+			tMax[i] = float32(math.Inf(1))
+			tDelta[i] = float32(math.Inf(1))
+		}
+	}
+	prev = pos
+	for t := float32(0); t <= maxDist; {
+		if block.Blocks[w.GetBlock(pos[0], pos[1], pos[2])].IsSolid() {
+			return pos, prev, true
+		}
+		axis := 0
+		if tMax[1] < tMax[axis] {
+			axis = 1
+		}
+		if tMax[2] < tMax[axis] {
+			axis = 2
+		}
+		prev = pos
+		pos[axis] += step[axis]
+		t = tMax[axis]
+		tMax[axis] += tDelta[axis]
+	}
+	return hit, prev, false
+}
+
 // Cleanup releases the world's resources.
 func (w *World) Cleanup() {
+	if w.Headless {
+		return
+	}
 	for _, chunk := range w.Chunks {
 		chunk.Cleanup()
 	}
-	gl.DeleteProgram(w.Program)
+	w.Program.Delete()
 	gl.DeleteTextures(1, &w.Texture)
+	w.cleanupSky()
+	w.cleanupLOD()
 }
 
-// Helper functions (shader and texture loading)
-const vertexShaderSource = `
-#version 460 core
-layout(location = 0) in vec3 position;
-layout(location = 1) in vec2 texCoord;
-layout(location = 2) in vec3 normal;
-out vec2 TexCoord;
-out vec3 Normal;
-out vec3 FragPos;
-uniform mat4 model;
-uniform mat4 view;
-uniform mat4 projection;
-void main() {
-    FragPos = vec3(model * vec4(position, 1.0));
-    Normal = mat3(transpose(inverse(model))) * normal;
-    gl_Position = projection * view * vec4(FragPos, 1.0);
-    TexCoord = texCoord;
-}
-`
-
-const fragmentShaderSource = `
-#version 460 core
-in vec2 TexCoord;
-in vec3 Normal;
-in vec3 FragPos;
-out vec4 fragColor;
-uniform sampler2D texture1;
-uniform vec3 lightDir;
-uniform vec3 viewPos;
-void main() {
-    vec3 norm = normalize(Normal);
-    vec3 lightDirection = normalize(-lightDir);
-    float diff = max(dot(norm, lightDirection), 0.0);
-    vec3 ambient = 0.1 * texture(texture1, TexCoord).rgb;
-    vec3 diffuse = diff * texture(texture1, TexCoord).rgb;
-    vec3 result = ambient + diffuse;
-    fragColor = vec4(result, 1.0);
-}
-`
-
 func loadTexture(path string) (uint32, error) {
 	imgFile, err := os.Open(path)
 	if err != nil {
@@ -183,48 +372,3 @@ func loadTexture(path string) (uint32, error) {
 	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
 	return texture, nil
 }
-
-func createShaderProgram(vertexSrc, fragmentSrc string) (uint32, error) {
-	vertexShader, err := compileShader(vertexSrc, gl.VERTEX_SHADER)
-	if err != nil {
-		return 0, err
-	}
-	fragmentShader, err := compileShader(fragmentSrc, gl.FRAGMENT_SHADER)
-	if err != nil {
-		return 0, err
-	}
-	prog := gl.CreateProgram()
-	gl.AttachShader(prog, vertexShader)
-	gl.AttachShader(prog, fragmentShader)
-	gl.LinkProgram(prog)
-	var status int32
-	gl.GetProgramiv(prog, gl.LINK_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetProgramiv(prog, gl.INFO_LOG_LENGTH, &logLength)
-		log := make([]byte, logLength+1)
-		gl.GetProgramInfoLog(prog, logLength, nil, &log[0])
-		return 0, fmt.Errorf("failed to link program: %s", log)
-	}
-	gl.DeleteShader(vertexShader)
-	gl.DeleteShader(fragmentShader)
-	return prog, nil
-}
-
-func compileShader(src string, shaderType uint32) (uint32, error) {
-	shader := gl.CreateShader(shaderType)
-	csources, free := gl.Strs(src + "\x00")
-	gl.ShaderSource(shader, 1, csources, nil)
-	free()
-	gl.CompileShader(shader)
-	var status int32
-	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
-		log := make([]byte, logLength+1)
-		gl.GetShaderInfoLog(shader, logLength, nil, &log[0])
-		return 0, fmt.Errorf("failed to compile shader: %s", log)
-	}
-	return shader, nil
-}
@@ -0,0 +1,108 @@
+// Package worldmap draws top-down images of the world, coloured by surface
+// block or biome and shaded by height. It runs on the CPU only, so it works
+// without a window or GPU.
+package worldmap
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"something/block"
+	"something/world"
+)
+
+// Mode chooses what a map's colours show.
+type Mode string
+
+const (
+	ByBlock Mode = "block"
+	ByBiome Mode = "biome"
+)
+
+// ParseMode converts a mode name from a flag or request.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(s); m {
+	case ByBlock, ByBiome:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown map mode %q (want block or biome)", s)
+}
+
+// BlockColors is the map colour of each surface block.
+var BlockColors = map[block.BlockID]color.RGBA{
+	block.BlockGrass: {95, 159, 53, 255},
+	block.BlockDirt:  {134, 96, 67, 255},
+	block.BlockStone: {125, 125, 125, 255},
+}
+
+// BiomeColors is the map colour of each biome.
+var BiomeColors = map[string]color.RGBA{
+	world.BiomePlains: {141, 179, 96, 255},
+	world.BiomeMeadow: {72, 150, 88, 255},
+	world.BiomeHills:  {150, 140, 110, 255},
+}
+
+// unknownColor is used for blocks or biomes missing from the tables.
+var unknownColor = color.RGBA{255, 0, 255, 255}
+
+const (
+	minBrightness = 0.65 // Brightness of the lowest columns; the highest are 1
+	slopeShade    = 0.08 // Brightness change per block of height difference
+	maxSlope      = 3    // Height differences beyond this shade no further
+)
+
+// Renderer draws maps of a source.
+type Renderer struct {
+	Source Source
+	Mode   Mode
+}
+
+// Render draws the blocks in area, given in block x and z with z down the
+// image, at scale blocks per pixel. Each pixel shows the column at its
+// centre.
+func (r *Renderer) Render(area image.Rectangle, scale int) *image.RGBA {
+	area = area.Canon()
+	scale = max(scale, 1)
+	width := (area.Dx() + scale - 1) / scale
+	height := (area.Dy() + scale - 1) / scale
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for py := 0; py < height; py++ {
+		for px := 0; px < width; px++ {
+			x := area.Min.X + px*scale + scale/2
+			z := area.Min.Y + py*scale + scale/2
+			c, ok := r.Source.Column(x, z)
+			if !ok {
+				continue
+			}
+			// Light comes from the north-west, one pixel away
+			slope := 0
+			if n, ok := r.Source.Column(x-scale, z-scale); ok {
+				slope = max(-maxSlope, min(maxSlope, c.Height-n.Height))
+			}
+			img.SetRGBA(px, py, r.shade(c, slope))
+		}
+	}
+	return img
+}
+
+// shade colours a column, brighter for higher columns and for slopes
+// facing the light.
+func (r *Renderer) shade(c Column, slope int) color.RGBA {
+	var base color.RGBA
+	var ok bool
+	if r.Mode == ByBiome {
+		base, ok = BiomeColors[c.Biome]
+	} else {
+		base, ok = BlockColors[c.Block]
+	}
+	if !ok {
+		base = unknownColor
+	}
+	f := minBrightness + (1-minBrightness)*float64(c.Height)/float64(world.ChunkSize-1)
+	f *= 1 + slopeShade*float64(slope)
+	scale := func(v uint8) uint8 {
+		return uint8(min(255, float64(v)*f))
+	}
+	return color.RGBA{scale(base.R), scale(base.G), scale(base.B), 255}
+}
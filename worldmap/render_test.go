@@ -0,0 +1,112 @@
+package worldmap
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"something/block"
+	"something/world"
+)
+
+// sourceFunc adapts a function to Source.
+type sourceFunc func(x, z int) (Column, bool)
+
+func (f sourceFunc) Column(x, z int) (Column, bool) { return f(x, z) }
+
+// flat is grass at height 8 everywhere.
+var flat = sourceFunc(func(x, z int) (Column, bool) {
+	return Column{Height: 8, Block: block.BlockGrass, Biome: world.BiomePlains}, true
+})
+
+func TestRenderSize(t *testing.T) {
+	r := &Renderer{Source: flat}
+	tests := []struct {
+		area          image.Rectangle
+		scale         int
+		width, height int
+	}{
+		{image.Rect(0, 0, 16, 8), 1, 16, 8},
+		{image.Rect(0, 0, 10, 7), 4, 3, 2},
+		{image.Rect(-10, -7, 0, 0), 4, 3, 2},
+		{image.Rect(10, 7, 0, 0), 4, 3, 2},
+		{image.Rect(0, 0, 10, 7), 0, 10, 7},
+		{image.Rect(0, 0, 3, 3), 16, 1, 1},
+	}
+	for _, tt := range tests {
+		img := r.Render(tt.area, tt.scale)
+		if b := img.Bounds(); b.Dx() != tt.width || b.Dy() != tt.height {
+			t.Errorf("Render(%v, %d) is %dx%d, want %dx%d", tt.area, tt.scale, b.Dx(), b.Dy(), tt.width, tt.height)
+		}
+	}
+
+	for zoom := 0; zoom <= MaxZoom; zoom++ {
+		img, err := r.Tile(zoom, -1, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := img.Bounds(); b.Dx() != TileSize || b.Dy() != TileSize {
+			t.Errorf("zoom %d tile is %dx%d, want %dx%d", zoom, b.Dx(), b.Dy(), TileSize, TileSize)
+		}
+	}
+	for _, zoom := range []int{-1, MaxZoom + 1} {
+		if _, err := r.Tile(zoom, 0, 0); err == nil {
+			t.Errorf("Tile at zoom %d succeeded", zoom)
+		}
+	}
+}
+
+func TestRenderSamples(t *testing.T) {
+	// Only columns west of x=0 are known
+	var sampled []image.Point
+	src := sourceFunc(func(x, z int) (Column, bool) {
+		sampled = append(sampled, image.Pt(x, z))
+		return Column{Height: 8, Block: block.BlockStone}, x < 0
+	})
+	r := &Renderer{Source: src}
+	img := r.Render(image.Rect(-8, -4, 8, 4), 4)
+	if b := img.Bounds(); b.Dx() != 4 || b.Dy() != 2 {
+		t.Fatalf("image is %v", b)
+	}
+	for px := 0; px < 4; px++ {
+		opaque := img.RGBAAt(px, 0).A == 255
+		if want := px < 2; opaque != want {
+			t.Errorf("pixel %d opaque %v, want %v", px, opaque, want)
+		}
+	}
+	// Each pixel samples its centre, then the column a pixel to the north-west
+	want := []image.Point{{-6, -2}, {-10, -6}, {-2, -2}, {-6, -6}, {2, -2}, {6, -2}}
+	for i, p := range want {
+		if sampled[i] != p {
+			t.Fatalf("sample %d at %v, want %v (all: %v)", i, sampled[i], p, sampled[:len(want)])
+		}
+	}
+}
+
+func TestShade(t *testing.T) {
+	col := func(h int, b block.BlockID, biome string) Column { return Column{Height: h, Block: b, Biome: biome} }
+	blocks := &Renderer{Mode: ByBlock}
+	brightness := func(c color.RGBA) int { return int(c.R) + int(c.G) + int(c.B) }
+
+	low, high := blocks.shade(col(0, block.BlockStone, ""), 0), blocks.shade(col(world.ChunkSize-1, block.BlockStone, ""), 0)
+	if high != BlockColors[block.BlockStone] {
+		t.Errorf("highest column %v, want the full colour %v", high, BlockColors[block.BlockStone])
+	}
+	if brightness(low) >= brightness(high) {
+		t.Errorf("low column %v is not darker than high column %v", low, high)
+	}
+	lit, shadowed := blocks.shade(col(8, block.BlockStone, ""), 2), blocks.shade(col(8, block.BlockStone, ""), -2)
+	if brightness(lit) <= brightness(shadowed) {
+		t.Errorf("slope facing the light %v is not brighter than one facing away %v", lit, shadowed)
+	}
+	if steep := blocks.shade(col(8, block.BlockStone, ""), -maxSlope); steep.A != 255 {
+		t.Errorf("steep slope is not opaque: %v", steep)
+	}
+	if got := blocks.shade(col(world.ChunkSize-1, block.BlockAir, ""), 0); got != unknownColor {
+		t.Errorf("unknown block shaded %v, want %v", got, unknownColor)
+	}
+	biomes := &Renderer{Mode: ByBiome}
+	if got := biomes.shade(col(world.ChunkSize-1, block.BlockStone, world.BiomeHills), 0); got != BiomeColors[world.BiomeHills] {
+		t.Errorf("biome mode shaded %v, want %v", got, BiomeColors[world.BiomeHills])
+	}
+}
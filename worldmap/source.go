@@ -0,0 +1,34 @@
+package worldmap
+
+import (
+	"something/block"
+	"something/world"
+)
+
+// Column is what the map needs to know about one column of blocks.
+type Column struct {
+	Height int // y of the top solid block
+	Block  block.BlockID
+	Biome  string
+}
+
+// Source reports the top of a column; ok is false where nothing is known,
+// which the map leaves transparent. Sources must be safe for concurrent
+// reads.
+type Source interface {
+	Column(x, z int) (c Column, ok bool)
+}
+
+// Generated reads columns straight from a seed's terrain generator, as
+// they are before any edits. Every column is known.
+type Generated struct {
+	gen *world.Generator
+}
+
+func NewGenerated(seed int64) *Generated {
+	return &Generated{gen: world.NewGenerator(seed)}
+}
+
+func (g *Generated) Column(x, z int) (Column, bool) {
+	return Column{Height: g.gen.Height(x, z), Block: block.BlockGrass, Biome: g.gen.Biome(x, z)}, true
+}
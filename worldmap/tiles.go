@@ -0,0 +1,97 @@
+package worldmap
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+	"strconv"
+
+	"something/capture"
+)
+
+// TileSize is the width and height of a tile in pixels.
+const TileSize = 256
+
+// MaxZoom is the most detailed zoom level, where a pixel is one block.
+// Each level below it halves the detail, so zoom 0 is 2^MaxZoom blocks
+// per pixel.
+const MaxZoom = 4
+
+// TileScale is the number of blocks per pixel at a zoom level.
+func TileScale(zoom int) int {
+	return 1 << (MaxZoom - zoom)
+}
+
+// TileBounds is the block area of tile x, y at a zoom level. Tile 0, 0
+// starts at the origin; tiles west or north of it have negative indices.
+func TileBounds(zoom, x, y int) image.Rectangle {
+	size := TileSize * TileScale(zoom)
+	return image.Rect(x*size, y*size, (x+1)*size, (y+1)*size)
+}
+
+// Tile draws tile x, y at a zoom level between 0 and MaxZoom.
+func (r *Renderer) Tile(zoom, x, y int) (*image.RGBA, error) {
+	if zoom < 0 || zoom > MaxZoom {
+		return nil, fmt.Errorf("zoom %d out of range 0-%d", zoom, MaxZoom)
+	}
+	return r.Render(TileBounds(zoom, x, y), TileScale(zoom)), nil
+}
+
+// TilePath is where a tile is stored under a tile directory.
+func TilePath(dir string, zoom, x, y int) string {
+	return filepath.Join(dir, strconv.Itoa(zoom), strconv.Itoa(x), strconv.Itoa(y)+".png")
+}
+
+// SaveTile draws one tile and writes it to TilePath.
+func (r *Renderer) SaveTile(dir string, zoom, x, y int) error {
+	img, err := r.Tile(zoom, x, y)
+	if err != nil {
+		return err
+	}
+	if err := capture.Save(img, TilePath(dir, zoom, x, y)); err != nil {
+		return fmt.Errorf("failed to write tile %d/%d/%d: %w", zoom, x, y, err)
+	}
+	return nil
+}
+
+// TileRange returns the inclusive tile indices that cover a block area at
+// a zoom level.
+func TileRange(zoom int, area image.Rectangle) (minX, minY, maxX, maxY int) {
+	area = area.Canon()
+	size := TileSize * TileScale(zoom)
+	return floorDiv(area.Min.X, size), floorDiv(area.Min.Y, size),
+		floorDiv(area.Max.X-1, size), floorDiv(area.Max.Y-1, size)
+}
+
+// ExportTiles writes every tile covering area, at zoom levels minZoom to
+// maxZoom, as dir/z/x/y.png. It returns the number of tiles written.
+func (r *Renderer) ExportTiles(dir string, area image.Rectangle, minZoom, maxZoom int) (int, error) {
+	if minZoom < 0 || maxZoom > MaxZoom || minZoom > maxZoom {
+		return 0, fmt.Errorf("zoom range %d-%d outside 0-%d", minZoom, maxZoom, MaxZoom)
+	}
+	area = area.Canon()
+	if area.Empty() {
+		return 0, fmt.Errorf("empty map area")
+	}
+	written := 0
+	for zoom := minZoom; zoom <= maxZoom; zoom++ {
+		minX, minY, maxX, maxY := TileRange(zoom, area)
+		for x := minX; x <= maxX; x++ {
+			for y := minY; y <= maxY; y++ {
+				if err := r.SaveTile(dir, zoom, x, y); err != nil {
+					return written, err
+				}
+				written++
+			}
+		}
+	}
+	return written, nil
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,99 @@
+package worldmap
+
+import (
+	"image"
+	"os"
+	"testing"
+)
+
+func TestFloorDiv(t *testing.T) {
+	tests := []struct{ a, b, want int }{
+		{7, 2, 3},
+		{-7, 2, -4},
+		{-8, 2, -4},
+		{0, 5, 0},
+		{-1, 256, -1},
+		{-256, 256, -1},
+		{-257, 256, -2},
+		{7, -2, -4},
+	}
+	for _, tt := range tests {
+		if got := floorDiv(tt.a, tt.b); got != tt.want {
+			t.Errorf("floorDiv(%d, %d) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestTileBounds(t *testing.T) {
+	tests := []struct {
+		zoom, x, y int
+		want       image.Rectangle
+	}{
+		{MaxZoom, 0, 0, image.Rect(0, 0, 256, 256)},
+		{MaxZoom, -1, 2, image.Rect(-256, 512, 0, 768)},
+		{MaxZoom - 1, 1, -1, image.Rect(512, -512, 1024, 0)},
+		{0, -1, 0, image.Rect(-4096, 0, 0, 4096)},
+	}
+	for _, tt := range tests {
+		if got := TileBounds(tt.zoom, tt.x, tt.y); got != tt.want {
+			t.Errorf("TileBounds(%d, %d, %d) = %v, want %v", tt.zoom, tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestTileRange(t *testing.T) {
+	tests := []struct {
+		name                   string
+		zoom                   int
+		area                   image.Rectangle
+		minX, minY, maxX, maxY int
+	}{
+		{"one tile", MaxZoom, image.Rect(0, 0, 256, 256), 0, 0, 0, 0},
+		{"one block past the edge", MaxZoom, image.Rect(0, 0, 257, 256), 0, 0, 1, 0},
+		{"around the origin", MaxZoom, image.Rect(-1, -1, 1, 1), -1, -1, 0, 0},
+		{"negative edge", MaxZoom, image.Rect(-256, -512, 0, 0), -1, -2, -1, -1},
+		{"negative past the edge", MaxZoom, image.Rect(-257, -1, -256, 0), -2, -1, -2, -1},
+		{"reversed corners", MaxZoom, image.Rect(1, 1, -1, -1), -1, -1, 0, 0},
+		{"coarse zoom", 0, image.Rect(-1, 0, 4097, 1), -1, 0, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minX, minY, maxX, maxY := TileRange(tt.zoom, tt.area)
+			if minX != tt.minX || minY != tt.minY || maxX != tt.maxX || maxY != tt.maxY {
+				t.Errorf("got %d,%d..%d,%d, want %d,%d..%d,%d", minX, minY, maxX, maxY, tt.minX, tt.minY, tt.maxX, tt.maxY)
+			}
+			// The tiles cover the area
+			for _, p := range []image.Point{tt.area.Canon().Min, tt.area.Canon().Max.Sub(image.Pt(1, 1))} {
+				if !p.In(TileBounds(tt.zoom, minX, minY).Union(TileBounds(tt.zoom, maxX, maxY))) {
+					t.Errorf("block %v is outside the tile range", p)
+				}
+			}
+		})
+	}
+}
+
+func TestExportTiles(t *testing.T) {
+	dir := t.TempDir()
+	r := &Renderer{Source: flat}
+	n, err := r.ExportTiles(dir, image.Rect(-1, 0, 300, 10), MaxZoom-1, MaxZoom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Zoom 4 needs tiles -1, 0 and 1 across; zoom 3 needs -1 and 0
+	if n != 5 {
+		t.Errorf("wrote %d tiles, want 5", n)
+	}
+	for _, tile := range [][3]int{{MaxZoom, -1, 0}, {MaxZoom, 1, 0}, {MaxZoom - 1, 0, 0}} {
+		if _, err := os.Stat(TilePath(dir, tile[0], tile[1], tile[2])); err != nil {
+			t.Errorf("tile %v: %v", tile, err)
+		}
+	}
+	for _, bad := range [][2]int{{-1, 2}, {3, MaxZoom + 1}, {3, 2}} {
+		if _, err := r.ExportTiles(dir, image.Rect(0, 0, 1, 1), bad[0], bad[1]); err == nil {
+			t.Errorf("zoom range %d-%d accepted", bad[0], bad[1])
+		}
+	}
+	if _, err := r.ExportTiles(dir, image.Rectangle{}, 0, 0); err == nil {
+		t.Error("empty area accepted")
+	}
+}